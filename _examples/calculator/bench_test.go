@@ -17,3 +17,15 @@ func BenchmarkCalculator(b *testing.B) {
 		expr.Parse(s)
 	}
 }
+
+func BenchmarkCalculatorExpression(b *testing.B) {
+	b.ReportAllocs()
+
+	test := "(1 + 2 * 3 + 9) * 2 + 1"
+	s := comb.NewStringScanner(test)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		calc.Parse(s)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/jakebailey/comb"
@@ -8,125 +9,149 @@ import (
 )
 
 var (
-	integer = whitespaceAround(combext.Integer())
-	addOp   = whitespaceAround(comb.Char('+', '-'))
-	mulOp   = whitespaceAround(comb.Char('*', '/'))
-	lParen  = whitespaceAround(comb.Char('('))
-	rParen  = whitespaceAround(comb.Char(')'))
+	integer = comb.Typed(whitespaceAround(combext.Integer()), int64Value)
+	addOp   = comb.Typed(whitespaceAround(comb.Char('+', '-')), firstRune)
+	mulOp   = comb.Typed(whitespaceAround(comb.Char('*', '/')), firstRune)
+	lParen  = comb.Typed(whitespaceAround(comb.Char('(')), firstRune)
+	rParen  = comb.Typed(whitespaceAround(comb.Char(')')), firstRune)
 )
 
+// expr and term are left recursive, so they are referred to with LeftRecOf.
 var (
-	expr   comb.Parser
-	term   comb.Parser
-	factor comb.Parser
+	expr    = comb.LeftRecOf(&sum)
+	term    = comb.LeftRecOf(&product)
+	sum     comb.TypedParser[int64]
+	product comb.TypedParser[int64]
+	factor  comb.TypedParser[int64]
 )
 
 func init() {
-	expr = comb.Sequence(
-		func(results []comb.Result, start, end comb.Scanner) comb.Result {
-			acc := results[0].Int64
-
-			l := results[1].Interface.([]comb.Result)
-			for i := 0; i < len(l); i += 2 {
-				op := l[i].Runes[0]
-				v := l[i+1].Int64
-
-				switch op {
-				case '+':
-					acc += v
-				case '-':
-					acc -= v
-				}
-			}
+	sum = comb.OrOf(
+		comb.Seq3(expr, addOp, term, apply),
+		term,
+	)
 
-			return comb.Result{
-				Int64: acc,
-			}
-		},
-		comb.Reference(&term),
-		comb.Many(
-			flatten,
-			comb.Sequence(
-				nil,
-				addOp,
-				comb.Reference(&term),
+	product = comb.OrOf(
+		comb.Seq3(term, mulOp, comb.ReferenceOf(&factor), apply),
+		comb.ReferenceOf(&factor),
+	)
+
+	factor = comb.LabelOf(
+		"expression",
+		comb.OrOf(
+			integer,
+			comb.Seq3(
+				lParen,
+				expr,
+				rParen,
+				func(_ rune, v int64, _ rune) int64 {
+					return v
+				},
 			),
 		),
 	)
+}
 
-	term = comb.Sequence(
-		func(results []comb.Result, start, end comb.Scanner) comb.Result {
-			acc := results[0].Int64
+// calc parses the same expressions as expr, with the addition of unary
+// minus, using Expression to handle precedence instead of left recursive
+// rules. The values are kept in Int64 rather than typed.
+var calc comb.Parser
 
-			l := results[1].Interface.([]comb.Result)
-			for i := 0; i < len(l); i += 2 {
-				op := l[i].Runes[0]
-				v := l[i+1].Int64
+func init() {
+	operand := comb.Label(
+		"expression",
+		comb.Or(
+			whitespaceAround(combext.Integer()),
+			comb.Surround(lParen, comb.Reference(&calc), rParen),
+		),
+	)
 
-				switch op {
-				case '*':
-					acc *= v
-				case '/':
-					acc /= v
+	calc = comb.Expression(operand, [][]comb.Operator{
+		{
+			comb.Prefix(operator('-'), func(op, x comb.Result) comb.Result {
+				return comb.Result{Int64: -x.Int64}
+			}),
+		},
+		{
+			comb.InfixLeft(operator('*'), func(x, op, y comb.Result) comb.Result {
+				return comb.Result{Int64: x.Int64 * y.Int64}
+			}),
+			comb.InfixLeft(operator('/'), func(x, op, y comb.Result) comb.Result {
+				if y.Int64 == 0 {
+					return comb.Failed(errors.New("division by zero"))
 				}
-			}
-
-			return comb.Result{
-				Int64: acc,
-			}
+				return comb.Result{Int64: x.Int64 / y.Int64}
+			}),
 		},
-		comb.Reference(&factor),
-		comb.Many(
-			flatten,
-			comb.Sequence(
-				nil,
-				mulOp,
-				comb.Reference(&term),
-			),
-		),
-	)
+		{
+			comb.InfixLeft(operator('+'), func(x, op, y comb.Result) comb.Result {
+				return comb.Result{Int64: x.Int64 + y.Int64}
+			}),
+			comb.InfixLeft(operator('-'), func(x, op, y comb.Result) comb.Result {
+				return comb.Result{Int64: x.Int64 - y.Int64}
+			}),
+		},
+	})
+}
 
-	factor = comb.Or(
-		integer,
-		comb.Surround(
-			lParen,
-			comb.Reference(&expr),
-			rParen,
-		),
-	)
+func operator(c rune) comb.Parser {
+	return whitespaceAround(comb.Char(c))
 }
 
+func apply(lhs int64, op rune, rhs int64) int64 {
+	switch op {
+	case '+':
+		return lhs + rhs
+	case '-':
+		return lhs - rhs
+	case '*':
+		return lhs * rhs
+	case '/':
+		return lhs / rhs
+	}
+	panic("unknown operator " + string(op))
+}
+
+func int64Value(r comb.Result) int64 {
+	return r.Int64
+}
+
+func firstRune(r comb.Result) rune {
+	return r.Runes[0]
+}
+
+var whitespace = comb.Label("whitespace", combext.ManyWhitespace())
+
 func whitespaceAround(p comb.Parser) comb.Parser {
 	return comb.Surround(
-		combext.ManyWhitespace(),
+		whitespace,
 		p,
-		combext.ManyWhitespace(),
+		whitespace,
 	)
 }
 
-func flatten(results []comb.Result, start, end comb.Scanner) comb.Result {
-	out := make([]comb.Result, 0, len(results)*2)
+func main() {
+	test := "(1 + 2 * 3 + 9) * 2 + 1"
+
+	s := comb.NewStringScanner(test)
 
-	for _, r := range results {
-		sub := r.Interface.([]comb.Result)
-		out = append(out, sub...)
-	}
+	r, next := comb.Parse(expr, s)
 
-	return comb.Result{
-		Interface: out,
+	if r.Matched() {
+		fmt.Printf("%v = %v\n", test, r.Interface)
+	} else {
+		fmt.Print(comb.FormatError(r, next, nil))
 	}
-}
 
-func main() {
-	test := "(1 + 2 * 3 + 9) * 2 + 1"
+	test = "-(1 + 2 * 3 + 9) * 2 + 1"
 
-	s := comb.NewStringScanner(test)
+	s = comb.NewStringScanner(test)
 
-	r, _ := expr.Parse(s)
+	r, next = comb.Parse(calc, s)
 
 	if r.Matched() {
 		fmt.Printf("%v = %v\n", test, r.Int64)
 	} else {
-		fmt.Println(r.Err)
+		fmt.Print(comb.FormatError(r, next, nil))
 	}
 }
@@ -1,17 +1,22 @@
 package comb
 
+import (
+	"fmt"
+	"strings"
+)
+
+var anyCharExpected = []string{"any character"}
+
 // AnyChar accepts any single character.
 func AnyChar() Parser {
 	return ParserFunc(func(s Scanner) (Result, Scanner) {
 		_, next, err := s.Next()
 
 		if err != nil {
-			return Failed(err), next
+			return failedCause(s, anyCharExpected, err), next
 		}
 
-		return Result{
-			Runes: s.Between(next),
-		}, next
+		return s.Result(next), next
 	})
 }
 
@@ -22,19 +27,19 @@ func Char(chars ...rune) Parser {
 		m[r] = struct{}{}
 	}
 
+	expected := quoteRunes(chars)
+
 	return ParserFunc(func(s Scanner) (Result, Scanner) {
 		r, next, err := s.Next()
 		if err != nil {
-			return Failed(err), next
+			return failedCause(s, expected, err), next
 		}
 
 		if _, ok := m[r]; !ok {
-			return Failedf("unexpected character '%c'", r), s
+			return FailedAt(s, expected...), s
 		}
 
-		return Result{
-			Runes: s.Between(next),
-		}, next
+		return s.Result(next), next
 	})
 }
 
@@ -48,13 +53,11 @@ func Take(n int) Parser {
 			_, next, err = next.Next()
 
 			if err != nil {
-				return Failed(err), next
+				return failedCause(next, anyCharExpected, err), next
 			}
 		}
 
-		return Result{
-			Runes: s.Between(next),
-		}, next
+		return s.Result(next), next
 	})
 }
 
@@ -65,37 +68,39 @@ func NotChar(chars ...rune) Parser {
 		m[r] = struct{}{}
 	}
 
+	expected := []string{
+		"any character except " + strings.Join(quoteRunes(chars), ", "),
+	}
+
 	return ParserFunc(func(s Scanner) (Result, Scanner) {
 		r, next, err := s.Next()
 		if err != nil {
-			return Failed(err), next
+			return failedCause(s, expected, err), next
 		}
 
 		if _, ok := m[r]; ok {
-			return Failedf("unexpected character '%c'", r), s
+			return FailedAt(s, expected...), s
 		}
 
-		return Result{
-			Runes: s.Between(next),
-		}, next
+		return s.Result(next), next
 	})
 }
 
 // CharRange accepts chars in an inclusive range.
 func CharRange(from, to rune) Parser {
+	expected := []string{fmt.Sprintf("'%c'-'%c'", from, to)}
+
 	return ParserFunc(func(s Scanner) (Result, Scanner) {
 		r, next, err := s.Next()
 		if err != nil {
-			return Failed(err), next
+			return failedCause(s, expected, err), next
 		}
 
 		if r < from || r > to {
-			return Failedf("unexpected character '%c'", r), s
+			return FailedAt(s, expected...), s
 		}
 
-		return Result{
-			Runes: s.Between(next),
-		}, next
+		return s.Result(next), next
 	})
 }
 
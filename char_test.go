@@ -1,6 +1,7 @@
 package comb
 
 import (
+	"errors"
 	"io"
 	"testing"
 
@@ -26,7 +27,7 @@ func TestAnyChar(t *testing.T) {
 
 	r, s = p.Parse(s)
 	assert.False(t, r.Matched())
-	assert.Equal(t, io.EOF, r.Err)
+	assert.True(t, errors.Is(r.Err, io.EOF))
 }
 
 func TestChar(t *testing.T) {
@@ -49,7 +50,7 @@ func TestChar(t *testing.T) {
 
 		r, s = p.Parse(s)
 		assert.False(t, r.Matched())
-		assert.EqualError(t, r.Err, "unexpected character 'd'")
+		assert.EqualError(t, r.Err, "line 1 col 4: expected one of 'a', 'b', 'c' but found 'd'")
 	})
 
 	t.Run("EOF", func(t *testing.T) {
@@ -63,7 +64,7 @@ func TestChar(t *testing.T) {
 
 		r, s = p.Parse(s)
 		assert.False(t, r.Matched())
-		assert.Equal(t, io.EOF, r.Err)
+		assert.True(t, errors.Is(r.Err, io.EOF))
 	})
 }
 
@@ -88,6 +89,7 @@ func TestTake(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune("abc"),
+			Span:  lineSpan(0, 3),
 		}
 
 		assert.True(t, r.Matched())
@@ -101,12 +103,8 @@ func TestTake(t *testing.T) {
 
 		r, next := p.Parse(s)
 
-		expected := Result{
-			Err: io.EOF,
-		}
-
 		assert.False(t, r.Matched())
-		assert.Equal(t, expected, r)
+		assert.True(t, errors.Is(r.Err, io.EOF))
 		assert.True(t, next.EOF())
 	})
 }
@@ -121,6 +119,7 @@ func TestNotChar(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune("d"),
+			Span:  lineSpan(0, 1),
 		}
 
 		assert.True(t, r.Matched())
@@ -134,7 +133,7 @@ func TestNotChar(t *testing.T) {
 		r, _ := p.Parse(s)
 
 		assert.False(t, r.Matched())
-		assert.EqualError(t, r.Err, "unexpected character 'a'")
+		assert.EqualError(t, r.Err, "line 1 col 1: expected any character except 'a', 'b', 'c' but found 'a'")
 	})
 
 	t.Run("EOF", func(t *testing.T) {
@@ -143,7 +142,7 @@ func TestNotChar(t *testing.T) {
 		r, _ := p.Parse(s)
 
 		assert.False(t, r.Matched())
-		assert.Equal(t, r.Err, io.EOF)
+		assert.True(t, errors.Is(r.Err, io.EOF))
 	})
 }
 
@@ -157,6 +156,7 @@ func TestCharRange(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune("3"),
+			Span:  lineSpan(0, 1),
 		}
 
 		assert.True(t, r.Matched())
@@ -170,7 +170,7 @@ func TestCharRange(t *testing.T) {
 		r, _ := p.Parse(s)
 
 		assert.False(t, r.Matched())
-		assert.EqualError(t, r.Err, "unexpected character 'a'")
+		assert.EqualError(t, r.Err, "line 1 col 1: expected '0'-'9' but found 'a'")
 	})
 
 	t.Run("EOF", func(t *testing.T) {
@@ -179,6 +179,6 @@ func TestCharRange(t *testing.T) {
 		r, _ := p.Parse(s)
 
 		assert.False(t, r.Matched())
-		assert.Equal(t, r.Err, io.EOF)
+		assert.True(t, errors.Is(r.Err, io.EOF))
 	})
 }
@@ -25,7 +25,7 @@ import (
 // 	),
 // )
 
-var integerParser = comb.Regexp(`-?0[xX][\da-fA-F]+|\d+`)
+var integerParser = comb.Regexp(`-?(?:0[xX][\da-fA-F]+|\d+)`)
 
 // Integer parses an integer in base 8, 10, or 16 using strconv.
 // It first applies ParseUint, then ParseInt, taking the first non-failing
@@ -40,10 +40,12 @@ func Integer() comb.Parser {
 
 		var i int64
 
-		rs := string(r.Runes)
+		rs := r.AsString()
 
 		if rs == "0" {
-			return comb.Result{}, next
+			return comb.Result{
+				Span: r.Span,
+			}, next
 		}
 
 		ui, err := strconv.ParseUint(rs, 0, 64)
@@ -59,6 +61,7 @@ func Integer() comb.Parser {
 
 		return comb.Result{
 			Int64: i,
+			Span:  r.Span,
 		}, next
 	})
 }
@@ -0,0 +1,51 @@
+package comb
+
+// Commit runs a parser, marking its result as committed if it fails.
+// A committed failure is final: Or and OrLongest will not try any other
+// alternatives, and Maybe, Many, and the other repetitions will not
+// backtrack over it. Instead, the failure is propagated unchanged.
+//
+// Commit is used once enough input has been matched to know which
+// alternative is being parsed, both to report errors where they occur
+// and to avoid needlessly trying other alternatives. When parsing from a
+// reader, it also lets input be released; see Scanner.Release.
+//
+//	ifStmt := comb.Sequence(nil, comb.Token("if"), comb.Commit(condAndBlock))
+func Commit(parser Parser) Parser {
+	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		s.st.commit(s)
+		r, next := parser.Parse(s)
+		if !r.Matched() {
+			r.Committed = true
+		}
+		return r, next
+	})
+}
+
+type cutParser struct{}
+
+func (cutParser) Parse(s Scanner) (Result, Scanner) {
+	return Result{Ignore: true}, s
+}
+
+// Cut marks a point in a Sequence or SequenceRunes after which failures are
+// committed, as if every following parser were wrapped in Commit. Cut
+// matches nothing, and its result is ignored. Like Commit, passing a Cut
+// lets the input of a reader be released. Outside of a sequence, Cut has
+// no effect.
+//
+//	ifStmt := comb.Sequence(nil, comb.Token("if"), comb.Cut(), cond, block)
+func Cut() Parser {
+	return cutParser{}
+}
+
+// cutIndex returns the index of the first Cut in parsers, or len(parsers)
+// if there is none.
+func cutIndex(parsers []Parser) int {
+	for i, p := range parsers {
+		if _, ok := p.(cutParser); ok {
+			return i
+		}
+	}
+	return len(parsers)
+}
@@ -0,0 +1,123 @@
+package comb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommit(t *testing.T) {
+	p := Or(
+		Sequence(nil, Token("if"), Commit(Char('('))),
+		Token("ident"),
+	)
+
+	t.Run("match", func(t *testing.T) {
+		s := NewStringScanner("if(")
+
+		r, next := p.Parse(s)
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+	})
+
+	t.Run("committed", func(t *testing.T) {
+		s := NewStringScanner("if x")
+
+		r, next := p.Parse(s)
+
+		assert.False(t, r.Matched())
+		assert.True(t, r.Committed)
+		assert.EqualError(t, r.Err, "line 1 col 3: expected '(' but found ' '")
+		assert.Equal(t, 2, next.i)
+	})
+
+	t.Run("not committed", func(t *testing.T) {
+		s := NewStringScanner("ident")
+
+		r, next := p.Parse(s)
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+	})
+
+	t.Run("Many", func(t *testing.T) {
+		p := ManyRunes(Sequence(nil, Char('a'), Commit(Char('b'))))
+		s := NewStringScanner("ababac")
+
+		r, _ := p.Parse(s)
+
+		assert.False(t, r.Matched())
+		assert.True(t, r.Committed)
+	})
+
+	t.Run("Maybe", func(t *testing.T) {
+		p := Maybe(Sequence(nil, Char('a'), Commit(Char('b'))))
+		s := NewStringScanner("ac")
+
+		r, _ := p.Parse(s)
+
+		assert.False(t, r.Matched())
+		assert.True(t, r.Committed)
+	})
+}
+
+func TestCut(t *testing.T) {
+	p := Or(
+		Sequence(nil, Token("if"), Cut(), Char('('), Char(')')),
+		SequenceRunes(Token("i"), Cut(), Char('x')),
+		Token("ident"),
+	)
+
+	t.Run("match", func(t *testing.T) {
+		s := NewStringScanner("if()")
+
+		r, next := p.Parse(s)
+
+		expected := Result{
+			Interface: []Result{
+				{Runes: []rune("if"), Span: lineSpan(0, 2)},
+				{Runes: []rune("("), Span: lineSpan(2, 3)},
+				{Runes: []rune(")"), Span: lineSpan(3, 4)},
+			},
+			Span: lineSpan(0, 4),
+		}
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, expected, r)
+		assert.True(t, next.EOF())
+	})
+
+	t.Run("committed", func(t *testing.T) {
+		s := NewStringScanner("if(]")
+
+		r, _ := p.Parse(s)
+
+		assert.False(t, r.Matched())
+		assert.True(t, r.Committed)
+		assert.EqualError(t, r.Err, "line 1 col 4: expected ')' but found ']'")
+	})
+
+	t.Run("runes", func(t *testing.T) {
+		s := NewStringScanner("id")
+
+		r, _ := p.Parse(s)
+
+		assert.False(t, r.Matched())
+		assert.True(t, r.Committed)
+		assert.EqualError(t, r.Err, "line 1 col 2: expected 'x' but found 'd'")
+	})
+
+	t.Run("before cut", func(t *testing.T) {
+		p := Or(
+			Sequence(nil, Char('a'), Char('b'), Cut(), Char('c')),
+			Token("ax"),
+		)
+		s := NewStringScanner("ax")
+
+		r, next := p.Parse(s)
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+	})
+}
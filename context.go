@@ -0,0 +1,31 @@
+package comb
+
+import "context"
+
+// contextCheckInterval is the number of steps between checks of
+// a parse's context.
+const contextCheckInterval = 256
+
+// WithContext attaches a context to a parse. The context is checked
+// periodically as the parse takes steps (see MaxSteps), and if it is done,
+// the parse stops, failing with a committed *ParseError wrapping ctx.Err(),
+// which can be checked with errors.Is.
+//
+//	ctx, cancel := context.WithTimeout(ctx, time.Second)
+//	defer cancel()
+//	r, next := comb.Parse(p, s, comb.WithContext(ctx))
+//	if errors.Is(r.Err, context.DeadlineExceeded) {
+//		// ...
+//	}
+func WithContext(ctx context.Context) Option {
+	return func(st *state) {
+		st.ctx = ctx
+	}
+}
+
+// checkContext stops the parse at s if its context is done.
+func (st *state) checkContext(s Scanner) {
+	if err := st.ctx.Err(); err != nil {
+		st.stop(s, err)
+	}
+}
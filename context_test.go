@@ -0,0 +1,59 @@
+package comb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithContext(t *testing.T) {
+	p := backtracking(func(p Parser) Parser { return p })
+	input := strings.Repeat("(", 20) + "x" + strings.Repeat(")", 20)
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		r, next := Parse(p, NewStringScanner(input), WithContext(ctx))
+
+		var pe *ParseError
+		assert.False(t, r.Matched())
+		assert.True(t, r.Committed)
+		assert.True(t, errors.Is(r.Err, context.Canceled))
+		assert.True(t, errors.As(r.Err, &pe))
+		assert.Equal(t, 0, next.Offset())
+	})
+
+	t.Run("during parse", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		steps := 0
+		var expr Parser
+		expr = Or(
+			Sequence(nil, Char('('), Reference(&expr), Char(')'), Char('+')),
+			Sequence(nil, Char('('), Reference(&expr), Char(')'), Char('-')),
+			ParserFunc(func(s Scanner) (Result, Scanner) {
+				if steps++; steps == 1000 {
+					cancel()
+				}
+				return Char('x').Parse(s)
+			}),
+		)
+
+		r, _ := Parse(expr, NewStringScanner(input), WithContext(ctx))
+
+		assert.False(t, r.Matched())
+		assert.True(t, errors.Is(r.Err, context.Canceled))
+		assert.True(t, steps < 1000+contextCheckInterval)
+	})
+
+	t.Run("not done", func(t *testing.T) {
+		r, _ := Parse(nested(), NewStringScanner("((x))"), WithContext(context.Background()))
+
+		assert.True(t, r.Matched())
+	})
+}
@@ -0,0 +1,8 @@
+//go:build combdebug
+
+package comb
+
+// debug enables checks which panic when a grammar has a bug, such as a
+// repetition of a parser which matches without consuming any input. It is
+// set by building with the combdebug build tag.
+const debug = true
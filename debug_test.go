@@ -0,0 +1,20 @@
+//go:build combdebug
+
+package comb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugStalled(t *testing.T) {
+	p := Many(nil, Label("maybe a", Maybe(Char('a'))))
+
+	defer func() {
+		assert.Equal(t, "comb: maybe a matched without consuming input at line 1 col 3", recover())
+	}()
+
+	p.Parse(NewStringScanner("aab"))
+	t.Error("expected a panic")
+}
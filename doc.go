@@ -58,13 +58,18 @@
 //
 // comb uses a scanner which traverses a rune slice. All builtin parsers
 // return results that are simply slices of the original data, keeping copying
-// to a minimum.
+// to a minimum. For large inputs, NewUTF8Scanner scans UTF-8 text directly
+// without converting it to runes, and NewReaderScanner reads lazily from an
+// io.Reader.
 //
 //
 // The combext package offers other general-use parsers (such as alpha-numeric
 // characters, whitespace, etc) that may be frequently needed, though not always
 // used.
 //
+// The peg package compiles grammars written in a PEG text format into comb
+// parsers at runtime, with actions bound to rules by name.
+//
 //
 // Examples
 //
@@ -1,6 +1,9 @@
 package comb
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type errorFunc func() string
 
@@ -13,3 +16,157 @@ func errorf(format string, a ...interface{}) error {
 		return fmt.Sprintf(format, a...)
 	})
 }
+
+// ParseError describes a failure at a position in the input. All builtin
+// parsers return a *ParseError when they fail.
+//
+// Like Failedf, a ParseError is not formatted until Error is called, so
+// creating one is cheap. Expected is typically computed once when a parser
+// is constructed and shared between every error it returns, so it must not
+// be modified.
+type ParseError struct {
+	// Scanner is the scanner at the position of the failure.
+	Scanner Scanner
+
+	// Expected describes what would have been accepted at the position,
+	// for example "'a'" or "EOF".
+	Expected []string
+
+	// Cause is the underlying error, if any, such as io.EOF.
+	Cause error
+
+	found  []rune
+	format string
+	args   []interface{}
+
+	// foundText is the saved result of Found, for errors which outlive
+	// the input at their position.
+	foundText string
+}
+
+// FailedAt returns a failed result holding a *ParseError at s, listing
+// the things that were expected there.
+func FailedAt(s Scanner, expected ...string) Result {
+	return Failed(&ParseError{
+		Scanner:  s,
+		Expected: expected,
+	})
+}
+
+// FailedAtf is like FailedAt, but uses a custom message in fmt.Errorf form
+// when nothing is expected. As with Failedf, the message is not formatted
+// until it is read.
+func FailedAtf(s Scanner, expected []string, format string, a ...interface{}) Result {
+	return Failed(&ParseError{
+		Scanner:  s,
+		Expected: expected,
+		format:   format,
+		args:     a,
+	})
+}
+
+func failedCause(s Scanner, expected []string, err error) Result {
+	return Failed(&ParseError{
+		Scanner:  s,
+		Expected: expected,
+		Cause:    err,
+	})
+}
+
+// Error formats the error as its Message prefixed by its position, in the
+// form "line L col C: expected X but found Y", or "name:L:C: expected X but
+// found Y" if the input is a File.
+func (e *ParseError) Error() string {
+	return e.Scanner.position() + ": " + e.Message()
+}
+
+// Message returns the error message without any position information. If
+// anything is expected, the message is "expected X but found Y". Otherwise,
+// the custom message is used if one was given, then the cause.
+func (e *ParseError) Message() string {
+	if len(e.Expected) > 0 {
+		return "expected " + joinExpected(e.Expected) + " but found " + e.Found()
+	}
+
+	if e.format != "" {
+		return fmt.Sprintf(e.format, e.args...)
+	}
+
+	if e.Cause != nil {
+		return e.Cause.Error()
+	}
+
+	return "unexpected " + e.Found()
+}
+
+// Unwrap returns the cause of the error.
+func (e *ParseError) Unwrap() error {
+	return e.Cause
+}
+
+// Found describes what was found at the position of the error,
+// such as "'x'" or "EOF".
+func (e *ParseError) Found() string {
+	if e.foundText != "" {
+		return e.foundText
+	}
+
+	if e.found != nil {
+		return fmt.Sprintf("%q", string(e.found))
+	}
+
+	r, _, err := e.Scanner.Next()
+	if err != nil {
+		return "EOF"
+	}
+
+	return fmt.Sprintf("'%c'", r)
+}
+
+// keepFound saves what was found at the position of an error from a reader
+// scanner, along with that of its causes, so that it can still be described
+// once the input there has been released.
+func keepFound(err error) {
+	for pe, ok := err.(*ParseError); ok && pe != nil; pe, ok = pe.Cause.(*ParseError) {
+		if pe.Scanner.stream != nil && pe.foundText == "" {
+			pe.foundText = pe.Found()
+		}
+	}
+}
+
+// Offset returns the offset of the error in the input, 0 indexed. This is
+// in runes, or in bytes for scanners created by NewUTF8Scanner or
+// NewBytesScanner.
+func (e *ParseError) Offset() int {
+	return e.Scanner.Offset()
+}
+
+// Pos returns the position of the error.
+func (e *ParseError) Pos() Pos {
+	return e.Scanner.Pos()
+}
+
+// Line returns the line number of the error, 1 indexed.
+func (e *ParseError) Line() int {
+	return e.Scanner.Line()
+}
+
+// Col returns the column number of the error, 1 indexed.
+func (e *ParseError) Col() int {
+	return e.Scanner.Col()
+}
+
+func joinExpected(expected []string) string {
+	if len(expected) == 1 {
+		return expected[0]
+	}
+	return "one of " + strings.Join(expected, ", ")
+}
+
+func quoteRunes(runes []rune) []string {
+	out := make([]string, len(runes))
+	for i, r := range runes {
+		out[i] = fmt.Sprintf("'%c'", r)
+	}
+	return out
+}
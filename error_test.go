@@ -0,0 +1,79 @@
+package comb
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseError(t *testing.T) {
+	t.Run("char", func(t *testing.T) {
+		p := Char('a', 'b')
+		_, s, _ := NewStringScanner("a\nxc").Next()
+		_, s, _ = s.Next()
+
+		r, _ := p.Parse(s)
+
+		var pe *ParseError
+		assert.True(t, errors.As(r.Err, &pe))
+		assert.Equal(t, 2, pe.Offset())
+		assert.Equal(t, 2, pe.Line())
+		assert.Equal(t, 1, pe.Col())
+		assert.Equal(t, []string{"'a'", "'b'"}, pe.Expected)
+		assert.Equal(t, "'x'", pe.Found())
+		assert.EqualError(t, r.Err, "line 2 col 1: expected one of 'a', 'b' but found 'x'")
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		p := Sequence(nil, Char('{'), Char(',', '}'))
+
+		r, _ := Parse(p, NewStringScanner("{]"))
+
+		assert.EqualError(t, r.Err, "line 1 col 2: expected one of ',', '}' but found ']'")
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		_, s, _ := NewStringScanner("ab").Next()
+
+		r := FailedAtf(s, nil, "bad %s", "thing")
+
+		assert.EqualError(t, r.Err, "line 1 col 2: bad thing")
+	})
+
+	t.Run("EOF", func(t *testing.T) {
+		p := CharRange('0', '9')
+		s := NewStringScanner("")
+
+		r, _ := p.Parse(s)
+
+		var pe *ParseError
+		assert.True(t, errors.As(r.Err, &pe))
+		assert.Equal(t, io.EOF, pe.Cause)
+		assert.Equal(t, "EOF", pe.Found())
+		assert.EqualError(t, r.Err, "line 1 col 1: expected '0'-'9' but found EOF")
+	})
+
+	t.Run("token", func(t *testing.T) {
+		p := Token("foobar", "fizzbuzz")
+		s := NewStringScanner("foobaz")
+
+		r, _ := p.Parse(s)
+
+		var pe *ParseError
+		assert.True(t, errors.As(r.Err, &pe))
+		assert.Equal(t, 0, pe.Offset())
+		assert.Equal(t, []string{`"foobar"`, `"fizzbuzz"`}, pe.Expected)
+		assert.Equal(t, `"foobaz"`, pe.Found())
+	})
+
+	t.Run("FailedAt", func(t *testing.T) {
+		s := NewStringScanner("]")
+
+		r := FailedAt(s, "','", "'}'")
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 1: expected one of ',', '}' but found ']'")
+	})
+}
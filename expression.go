@@ -0,0 +1,303 @@
+package comb
+
+// UnaryFold combines a prefix or postfix operator with its operand.
+type UnaryFold func(op, operand Result) Result
+
+// BinaryFold combines an infix operator with its operands.
+type BinaryFold func(lhs, op, rhs Result) Result
+
+type operatorKind int
+
+const (
+	prefixOperator operatorKind = iota
+	postfixOperator
+	infixLeftOperator
+	infixRightOperator
+	infixNoneOperator
+)
+
+// Operator is an operator in an Expression's precedence table.
+type Operator struct {
+	kind   operatorKind
+	parser Parser
+	unary  UnaryFold
+	binary BinaryFold
+}
+
+// Prefix is an operator matched by parser before its operand,
+// such as negation.
+func Prefix(parser Parser, fold UnaryFold) Operator {
+	return Operator{kind: prefixOperator, parser: parser, unary: fold}
+}
+
+// Postfix is an operator matched by parser after its operand,
+// such as a factorial.
+func Postfix(parser Parser, fold UnaryFold) Operator {
+	return Operator{kind: postfixOperator, parser: parser, unary: fold}
+}
+
+// InfixLeft is a left associative binary operator, so that "a-b-c"
+// is folded as "(a-b)-c".
+func InfixLeft(parser Parser, fold BinaryFold) Operator {
+	return Operator{kind: infixLeftOperator, parser: parser, binary: fold}
+}
+
+// InfixRight is a right associative binary operator, so that "a^b^c"
+// is folded as "a^(b^c)".
+func InfixRight(parser Parser, fold BinaryFold) Operator {
+	return Operator{kind: infixRightOperator, parser: parser, binary: fold}
+}
+
+// InfixNone is a non-associative binary operator, which cannot be chained,
+// so that "a==b==c" fails.
+func InfixNone(parser Parser, fold BinaryFold) Operator {
+	return Operator{kind: infixNoneOperator, parser: parser, binary: fold}
+}
+
+// Expression builds a parser for expressions of operands and operators.
+// The table lists the operators at each level of precedence, from the
+// highest to the lowest. For example:
+//
+//	expr = comb.Expression(number, [][]comb.Operator{
+//		{comb.Prefix(comb.Char('-'), negate)},
+//		{comb.InfixLeft(comb.Char('*'), mul), comb.InfixLeft(comb.Char('/'), div)},
+//		{comb.InfixLeft(comb.Char('+'), add), comb.InfixLeft(comb.Char('-'), sub)},
+//	})
+//
+// At each level, any number of prefix operators may precede an operand,
+// and any number of postfix operators may follow it. Prefix operators
+// are applied before postfix operators. Infix operators at the same level
+// must all have the same associativity to be chained.
+//
+// Operators are folded into a single result as they are parsed. If a fold
+// does not set the span of its result, it is set to cover the operator and
+// its operands. If a fold returns a failed result, the expression fails.
+//
+// As with Many, if an operand cannot be parsed after an infix or postfix
+// operator, the operator is left unparsed. To parse parenthesized
+// expressions, refer back to the expression in the operand:
+//
+//	number := comb.Or(integer, comb.Surround(lParen, comb.Reference(&expr), rParen))
+func Expression(operand Parser, table [][]Operator) Parser {
+	p := operand
+	for _, ops := range table {
+		p = expressionLevel(p, ops)
+	}
+	return p
+}
+
+func expressionLevel(term Parser, ops []Operator) Parser {
+	var prefix, postfix, infix []Operator
+
+	for _, op := range ops {
+		switch op.kind {
+		case prefixOperator:
+			prefix = append(prefix, op)
+		case postfixOperator:
+			postfix = append(postfix, op)
+		default:
+			infix = append(infix, op)
+		}
+	}
+
+	if len(prefix) > 0 || len(postfix) > 0 {
+		term = unaryExpression(term, prefix, postfix)
+	}
+
+	if len(infix) == 0 {
+		return term
+	}
+
+	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		mark := s.st.begin()
+		pin := s.st.pin(s)
+
+		lhs, next := term.Parse(s)
+		if !lhs.Matched() {
+			s.st.unpin(pin)
+			return s.st.fail(mark, lhs), next
+		}
+
+		operands := []expressionOperand{{r: lhs, start: s, end: next}}
+		var operators []expressionOperator
+		var kind operatorKind
+
+		for {
+			i, opR, opNext := matchOperator(infix, next)
+			if i < 0 {
+				if opR.Committed {
+					s.st.unpin(pin)
+					return s.st.fail(mark, opR), opNext
+				}
+				break
+			}
+
+			if len(operators) > 0 {
+				switch {
+				case kind == infixNoneOperator:
+					s.st.unpin(pin)
+					return s.st.reject(mark, FailedAtf(next, nil, "ambiguous use of non-associative operator")), next
+				case infix[i].kind != kind:
+					s.st.unpin(pin)
+					return s.st.reject(mark, FailedAtf(next, nil, "ambiguous use of operators with different associativity")), next
+				}
+			}
+
+			rhs, rhsNext := term.Parse(opNext)
+			if !rhs.Matched() {
+				if rhs.Committed {
+					s.st.unpin(pin)
+					return s.st.fail(mark, rhs), rhsNext
+				}
+				s.st.record(rhs.Err)
+				break
+			}
+
+			kind = infix[i].kind
+			operators = append(operators, expressionOperator{r: opR, at: next, fold: infix[i].binary})
+			operands = append(operands, expressionOperand{r: rhs, start: opNext, end: rhsNext})
+			next = rhsNext
+		}
+
+		r := foldBinary(kind, operands, operators)
+		if !r.Matched() {
+			s.st.unpin(pin)
+			return s.st.reject(mark, r), next
+		}
+
+		s.st.end(mark, nil)
+		s.st.unpin(pin)
+		return r, next
+	})
+}
+
+// expressionOperand is a parsed operand of an infix operator.
+type expressionOperand struct {
+	r          Result
+	start, end Scanner
+}
+
+// expressionOperator is a parsed infix operator.
+type expressionOperator struct {
+	r    Result
+	at   Scanner
+	fold BinaryFold
+}
+
+func unaryExpression(term Parser, prefix, postfix []Operator) Parser {
+	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		mark := s.st.begin()
+		pin := s.st.pin(s)
+		next := s
+
+		var operators []expressionOperator
+		var folds []UnaryFold
+
+		for len(prefix) > 0 {
+			i, opR, opNext := matchOperator(prefix, next)
+			if i < 0 {
+				if opR.Committed {
+					s.st.unpin(pin)
+					return s.st.fail(mark, opR), opNext
+				}
+				break
+			}
+
+			operators = append(operators, expressionOperator{r: opR, at: next})
+			folds = append(folds, prefix[i].unary)
+			next = opNext
+		}
+
+		r, next := term.Parse(next)
+		if !r.Matched() {
+			s.st.unpin(pin)
+			return s.st.fail(mark, r), next
+		}
+
+		// Prefix operators are applied from the innermost outwards.
+		for i := len(operators) - 1; i >= 0 && r.Matched(); i-- {
+			op := operators[i]
+			r = foldResult(folds[i](op.r, r), op.at, op.at, next)
+		}
+
+		for len(postfix) > 0 && r.Matched() {
+			i, opR, opNext := matchOperator(postfix, next)
+			if i < 0 {
+				if opR.Committed {
+					s.st.unpin(pin)
+					return s.st.fail(mark, opR), opNext
+				}
+				break
+			}
+
+			r = foldResult(postfix[i].unary(opR, r), next, s, opNext)
+			next = opNext
+		}
+
+		if !r.Matched() {
+			s.st.unpin(pin)
+			return s.st.reject(mark, r), next
+		}
+
+		s.st.end(mark, nil)
+		s.st.unpin(pin)
+		return r, next
+	})
+}
+
+// matchOperator tries each operator in turn, returning the index of the
+// first to match along with its result. If none match, the index is -1,
+// and the result is a committed failure if one stopped the search.
+func matchOperator(ops []Operator, s Scanner) (int, Result, Scanner) {
+	for i, op := range ops {
+		r, next := op.parser.Parse(s)
+		if r.Matched() {
+			return i, r, next
+		}
+		if r.Committed {
+			return -1, r, next
+		}
+		s.st.record(r.Err)
+	}
+
+	return -1, Result{}, s
+}
+
+// foldBinary folds a chain of infix operators of the given kind.
+func foldBinary(kind operatorKind, operands []expressionOperand, operators []expressionOperator) Result {
+	if kind == infixRightOperator {
+		last := operands[len(operands)-1]
+		r := last.r
+		for i := len(operators) - 1; i >= 0 && r.Matched(); i-- {
+			op, lhs := operators[i], operands[i]
+			r = foldResult(op.fold(lhs.r, op.r, r), op.at, lhs.start, last.end)
+		}
+		return r
+	}
+
+	first := operands[0]
+	r := first.r
+	for i := 0; i < len(operators) && r.Matched(); i++ {
+		op, rhs := operators[i], operands[i+1]
+		r = foldResult(op.fold(r, op.r, rhs.r), op.at, first.start, rhs.end)
+	}
+	return r
+}
+
+// foldResult finishes the result of a fold of an operator at the scanner
+// at, over the input from start to end. If the fold did not set the span,
+// it is set. If the fold failed with an error which is not a *ParseError,
+// the error is wrapped in one at the operator.
+func foldResult(r Result, at, start, end Scanner) Result {
+	if !r.Matched() {
+		if _, ok := r.Err.(*ParseError); !ok {
+			r.Err = &ParseError{Scanner: at, Cause: r.Err}
+		}
+		return r
+	}
+
+	if !r.Span.IsValid() {
+		r.Span = start.Span(end)
+	}
+	return r
+}
@@ -0,0 +1,153 @@
+package comb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func foldInfix(lhs, op, rhs Result) Result {
+	runes := []rune{'('}
+	runes = append(runes, lhs.Runes...)
+	runes = append(runes, op.Runes...)
+	runes = append(runes, rhs.Runes...)
+	runes = append(runes, ')')
+	return Result{Runes: runes}
+}
+
+func foldPrefix(op, operand Result) Result {
+	runes := []rune{'('}
+	runes = append(runes, op.Runes...)
+	runes = append(runes, operand.Runes...)
+	runes = append(runes, ')')
+	return Result{Runes: runes}
+}
+
+func foldPostfix(op, operand Result) Result {
+	runes := []rune{'('}
+	runes = append(runes, operand.Runes...)
+	runes = append(runes, op.Runes...)
+	runes = append(runes, ')')
+	return Result{Runes: runes}
+}
+
+func TestExpression(t *testing.T) {
+	var expr Parser
+	operand := Or(
+		CharRange('a', 'z'),
+		Surround(Char('['), Reference(&expr), Char(']')),
+	)
+
+	expr = Expression(operand, [][]Operator{
+		{Postfix(Char('!'), foldPostfix)},
+		{Prefix(Char('-'), foldPrefix)},
+		{InfixRight(Char('^'), foldInfix)},
+		{InfixLeft(Char('*'), foldInfix), InfixLeft(Char('/'), foldInfix)},
+		{InfixLeft(Char('+'), foldInfix), InfixLeft(Char('-'), foldInfix)},
+		{InfixNone(Token("=="), foldInfix), InfixNone(Char('<'), foldInfix)},
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a", "a"},
+		{"a+b", "(a+b)"},
+		{"a-b-c", "((a-b)-c)"},
+		{"a^b^c", "(a^(b^c))"},
+		{"a+b*c", "(a+(b*c))"},
+		{"a*b/c+d", "(((a*b)/c)+d)"},
+		{"[a+b]*c", "((a+b)*c)"},
+		{"-a^b", "((-a)^b)"},
+		{"--a!", "(-(-(a!)))"},
+		{"a!!", "((a!)!)"},
+		{"a+b==c", "((a+b)==c)"},
+		{"a-b<-c", "((a-b)<(-c))"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			r, next := Parse(expr, NewStringScanner(test.input))
+
+			assert.True(t, r.Matched())
+			assert.True(t, next.EOF())
+			assert.Equal(t, test.expected, string(r.Runes))
+			assert.Equal(t, lineSpan(0, len(test.input)), r.Span)
+		})
+	}
+
+	t.Run("trailing operator", func(t *testing.T) {
+		r, next := Parse(expr, NewStringScanner("a+b*"))
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, "(a+b)", string(r.Runes))
+		assert.Equal(t, 3, next.Offset())
+	})
+
+	t.Run("missing operand", func(t *testing.T) {
+		r, _ := Parse(Sequence(nil, expr, EOF()), NewStringScanner("a+*"))
+
+		var pe *ParseError
+		assert.False(t, r.Matched())
+		assert.True(t, errors.As(r.Err, &pe))
+		assert.Equal(t, 2, pe.Offset())
+	})
+
+	t.Run("non-associative", func(t *testing.T) {
+		r, _ := Parse(expr, NewStringScanner("a==b<c"))
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 5: ambiguous use of non-associative operator")
+	})
+
+	t.Run("no operand", func(t *testing.T) {
+		r, _ := Parse(expr, NewStringScanner("*"))
+
+		var pe *ParseError
+		assert.False(t, r.Matched())
+		assert.True(t, errors.As(r.Err, &pe))
+		assert.Equal(t, []string{"'-'", "'a'-'z'", "'['"}, pe.Expected)
+	})
+}
+
+func TestExpressionFold(t *testing.T) {
+	integer := Sequence(
+		func(results []Result, begin, end Scanner) Result {
+			return Result{Int64: int64(results[0].Runes[0] - '0')}
+		},
+		CharRange('0', '9'),
+	)
+
+	div := func(lhs, op, rhs Result) Result {
+		if rhs.Int64 == 0 {
+			return Failed(errors.New("division by zero"))
+		}
+		return Result{Int64: lhs.Int64 / rhs.Int64}
+	}
+	sub := func(lhs, op, rhs Result) Result {
+		return Result{Int64: lhs.Int64 - rhs.Int64}
+	}
+
+	p := Expression(integer, [][]Operator{
+		{InfixLeft(Char('/'), div)},
+		{InfixLeft(Char('-'), sub)},
+	})
+
+	t.Run("value", func(t *testing.T) {
+		r, _ := Parse(p, NewStringScanner("9-8/4-1"))
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, int64(6), r.Int64)
+	})
+
+	t.Run("failed fold", func(t *testing.T) {
+		r, _ := Parse(p, NewStringScanner("9-8/0"))
+
+		var pe *ParseError
+		assert.False(t, r.Matched())
+		assert.True(t, errors.As(r.Err, &pe))
+		assert.Equal(t, 3, pe.Offset())
+		assert.EqualError(t, r.Err, "line 1 col 4: division by zero")
+	})
+}
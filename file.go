@@ -0,0 +1,111 @@
+package comb
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// File is a named input in a FileSet. Positions in a file are offset by
+// its base, so that positions from every file in the set are distinct.
+type File struct {
+	name string
+	base int
+	size int
+}
+
+// Name returns the name of the file.
+func (f *File) Name() string {
+	return f.name
+}
+
+// Base returns the offset of the first position in the file.
+func (f *File) Base() int {
+	return f.base
+}
+
+// Size returns the size of the file, in the same units as Pos.Offset.
+func (f *File) Size() int {
+	return f.size
+}
+
+// Position returns a position in the file in "name:line:col" form.
+func (f *File) Position(p Pos) string {
+	return fmt.Sprintf("%s:%d:%d", f.name, p.Line, p.Col)
+}
+
+// FileSet hands out non-overlapping ranges of positions to many files,
+// so that positions from all of them can be stored together (for example,
+// in one AST) and later resolved back to their file. It is safe for
+// concurrent use.
+type FileSet struct {
+	mu    sync.RWMutex
+	base  int
+	files []*File
+}
+
+// NewFileSet creates a new, empty FileSet.
+func NewFileSet() *FileSet {
+	return &FileSet{}
+}
+
+// AddFile adds a file to the set with a given name and size. The size is
+// in runes, or in bytes for files scanned with NewUTF8Scanner or
+// NewBytesScanner, and must be at least the length of the input.
+func (fs *FileSet) AddFile(name string, size int) *File {
+	if size < 0 {
+		panic("comb: negative file size")
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	f := &File{
+		name: name,
+		base: fs.base,
+		size: size,
+	}
+
+	// Leave room for the position at EOF.
+	fs.base += size + 1
+	fs.files = append(fs.files, f)
+
+	return f
+}
+
+// NewScanner adds a file to the set, and returns a UTF-8 scanner over its
+// contents, like NewUTF8Scanner. Positions from the scanner belong to the
+// new file.
+func (fs *FileSet) NewScanner(name, src string) Scanner {
+	return NewUTF8Scanner(src).WithFile(fs.AddFile(name, len(src)))
+}
+
+// File returns the file containing a position, or nil if there is none.
+func (fs *FileSet) File(p Pos) *File {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	i := sort.Search(len(fs.files), func(i int) bool {
+		return fs.files[i].base > p.Offset
+	}) - 1
+
+	if i < 0 {
+		return nil
+	}
+
+	f := fs.files[i]
+	if p.Offset > f.base+f.size {
+		return nil
+	}
+
+	return f
+}
+
+// Position returns a position in "name:line:col" form, or "line:col"
+// if the position does not belong to a file in the set.
+func (fs *FileSet) Position(p Pos) string {
+	if f := fs.File(p); f != nil {
+		return f.Position(p)
+	}
+	return p.String()
+}
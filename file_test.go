@@ -0,0 +1,67 @@
+package comb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSet(t *testing.T) {
+	fs := NewFileSet()
+	p := SequenceRunes(Token("include"), Char(' '), OnePlusRunes(CharRange('a', 'z')))
+
+	s1 := fs.NewScanner("a.conf", "include b")
+	s2 := fs.NewScanner("b.conf", "\ninclude c")
+
+	f1 := s1.File()
+	f2 := s2.File()
+
+	assert.Equal(t, "a.conf", f1.Name())
+	assert.Equal(t, 0, f1.Base())
+	assert.Equal(t, 9, f1.Size())
+	assert.Equal(t, "b.conf", f2.Name())
+	assert.Equal(t, 10, f2.Base())
+
+	r1, _ := p.Parse(s1)
+	assert.True(t, r1.Matched())
+
+	_, s2, _ = s2.Next()
+	r2, _ := p.Parse(s2)
+	assert.True(t, r2.Matched())
+
+	assert.Equal(t, Pos{Offset: 0, Line: 1, Col: 1}, r1.Span.Start)
+	assert.Equal(t, Pos{Offset: 11, Line: 2, Col: 1}, r2.Span.Start)
+	assert.Equal(t, 9, r2.Span.Len())
+
+	assert.Equal(t, f1, fs.File(r1.Span.Start))
+	assert.Equal(t, f1, fs.File(r1.Span.End))
+	assert.Equal(t, f2, fs.File(r2.Span.Start))
+	assert.Equal(t, f2, fs.File(r2.Span.End))
+	assert.Nil(t, fs.File(Pos{Offset: 100}))
+
+	assert.Equal(t, "a.conf:1:10", fs.Position(r1.Span.End))
+	assert.Equal(t, "b.conf:2:1", fs.Position(r2.Span.Start))
+	assert.Equal(t, "1:1", NewFileSet().Position(r1.Span.Start))
+}
+
+func TestFileErrors(t *testing.T) {
+	fs := NewFileSet()
+	fs.AddFile("other.conf", 100)
+
+	p := Or(Char('a'), Char('b'))
+	s := fs.NewScanner("test.conf", "\nx")
+	_, s, _ = s.Next()
+
+	r, next := p.Parse(s)
+
+	assert.False(t, r.Matched())
+	assert.EqualError(t, r.Err, "test.conf:2:1: expected one of 'a', 'b' but found 'x'")
+	assert.Equal(t, 1, r.Err.(*ParseError).Offset())
+	assert.Equal(t, 102, r.Err.(*ParseError).Pos().Offset)
+
+	expected := "test.conf:2:1: expected one of 'a', 'b' but found 'x'\n" +
+		" 2 | x\n" +
+		"   | ^\n"
+
+	assert.Equal(t, expected, FormatError(r, next, nil))
+}
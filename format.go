@@ -0,0 +1,142 @@
+package comb
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+const (
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[31m"
+	ansiBlue  = "\x1b[34m"
+	ansiReset = "\x1b[0m"
+)
+
+// ErrorFormat configures FormatError.
+type ErrorFormat struct {
+	// Color enables ANSI color codes in the output.
+	Color bool
+
+	// Context is the number of lines shown before the line
+	// containing the error.
+	Context int
+}
+
+// FormatError renders the error of a failed result for display, showing
+// the message, the offending line of source, and a caret under the column
+// where the failure occurred. s should be the scanner returned alongside
+// the failed result, and is used to locate the failure if the error is not
+// a *ParseError. If f is nil, the default format is used.
+//
+// For example, with a Context of 1:
+//
+//	line 2 col 5: expected one of ',', '}' but found ']'
+//	  1 | {a,
+//	  2 | b, c]
+//	    |     ^
+func FormatError(r Result, s Scanner, f *ErrorFormat) string {
+	if r.Matched() {
+		return ""
+	}
+
+	if f == nil {
+		f = &ErrorFormat{}
+	}
+
+	msg := r.Err.Error()
+
+	if pe, ok := r.Err.(*ParseError); ok {
+		s = pe.Scanner
+		msg = pe.Message()
+	}
+
+	var buf bytes.Buffer
+
+	header := s.position() + ":"
+	if f.Color {
+		header = ansiBold + header + ansiReset
+		msg = ansiBold + ansiRed + msg + ansiReset
+	}
+	fmt.Fprintf(&buf, "%s %s\n", header, msg)
+
+	// Make sure the rest of the line has been read, for reader scanners.
+	for eol := s; ; {
+		c, next, err := eol.Next()
+		if err != nil || c == '\n' {
+			break
+		}
+		eol = next
+	}
+
+	runes, i := s.around(f.Context)
+
+	start, end := lineBounds(runes, i)
+
+	width := len(strconv.Itoa(s.Line()))
+	gutter := func(n int) string {
+		g := fmt.Sprintf(" %*s | ", width, "")
+		if n > 0 {
+			g = fmt.Sprintf(" %*d | ", width, n)
+		}
+		if f.Color {
+			g = ansiBlue + g + ansiReset
+		}
+		return g
+	}
+
+	// Walk backwards to find the start of the first context line,
+	// stopping early if the input has been released.
+	first := s.Line()
+	ctxStart := start
+	for n := 0; n < f.Context && first > 1 && ctxStart > 0; n++ {
+		ctxStart, _ = lineBounds(runes, ctxStart-1)
+		first--
+	}
+
+	for n := first; n < s.Line(); n++ {
+		ls, le := lineBounds(runes, ctxStart)
+		fmt.Fprintf(&buf, "%s%s\n", gutter(n), string(runes[ls:le]))
+		ctxStart = le + 1
+	}
+
+	fmt.Fprintf(&buf, "%s%s\n", gutter(s.Line()), string(runes[start:end]))
+
+	// Keep tabs in the caret line so that it lines up with the source.
+	caret := make([]rune, 0, i-start+1)
+	for _, c := range runes[start:i] {
+		if c == '\t' {
+			caret = append(caret, '\t')
+		} else {
+			caret = append(caret, ' ')
+		}
+	}
+
+	mark := "^"
+	if f.Color {
+		mark = ansiBold + ansiRed + mark + ansiReset
+	}
+	fmt.Fprintf(&buf, "%s%s%s\n", gutter(0), string(caret), mark)
+
+	return buf.String()
+}
+
+// lineBounds returns the range [start, end) of the line containing i,
+// not including the newline.
+func lineBounds(runes []rune, i int) (start, end int) {
+	if i > len(runes) {
+		i = len(runes)
+	}
+
+	start = i
+	for start > 0 && runes[start-1] != '\n' {
+		start--
+	}
+
+	end = i
+	for end < len(runes) && runes[end] != '\n' {
+		end++
+	}
+
+	return start, end
+}
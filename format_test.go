@@ -0,0 +1,71 @@
+package comb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatError(t *testing.T) {
+	item := CharRange('a', 'z')
+	p := Sequence(
+		nil,
+		Char('{'),
+		item,
+		Many(nil, Sequence(nil, Char(','), Many(nil, Char(' ', '\t', '\n')), item)),
+		Char('}'),
+	)
+
+	t.Run("context", func(t *testing.T) {
+		s := NewStringScanner("{a,\nb, c]")
+
+		r, next := Parse(p, s)
+
+		expected := "line 2 col 5: expected one of ',', '}' but found ']'\n" +
+			" 1 | {a,\n" +
+			" 2 | b, c]\n" +
+			"   |     ^\n"
+
+		assert.Equal(t, expected, FormatError(r, next, &ErrorFormat{Context: 1}))
+	})
+
+	t.Run("tabs", func(t *testing.T) {
+		s := NewStringScanner("{a,\n\tb]")
+
+		r, next := Parse(p, s)
+
+		expected := "line 2 col 3: expected one of ',', '}' but found ']'\n" +
+			" 2 | \tb]\n" +
+			"   | \t ^\n"
+
+		assert.Equal(t, expected, FormatError(r, next, nil))
+	})
+
+	t.Run("color", func(t *testing.T) {
+		s := NewStringScanner("x")
+
+		r, next := Parse(Char('{'), s)
+
+		expected := ansiBold + "line 1 col 1:" + ansiReset + " " +
+			ansiBold + ansiRed + "expected '{' but found 'x'" + ansiReset + "\n" +
+			ansiBlue + " 1 | " + ansiReset + "x\n" +
+			ansiBlue + "   | " + ansiReset + ansiBold + ansiRed + "^" + ansiReset + "\n"
+
+		assert.Equal(t, expected, FormatError(r, next, &ErrorFormat{Color: true}))
+	})
+
+	t.Run("other error", func(t *testing.T) {
+		s := NewStringScanner("ab")
+		_, next, _ := s.Next()
+
+		expected := "line 1 col 2: oops\n" +
+			" 1 | ab\n" +
+			"   |  ^\n"
+
+		assert.Equal(t, expected, FormatError(Failedf("oops"), next, nil))
+	})
+
+	t.Run("matched", func(t *testing.T) {
+		assert.Equal(t, "", FormatError(Result{}, NewStringScanner(""), nil))
+	})
+}
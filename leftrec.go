@@ -0,0 +1,137 @@
+package comb
+
+// LeftRec is like Reference, but allows the referenced parser to be left
+// recursive, so that a rule can be written as it would be in a grammar:
+//
+//	var expr comb.Parser
+//	expr = comb.Or(
+//		comb.Sequence(sub, comb.LeftRec(&expr), comb.Char('-'), number),
+//		number,
+//	)
+//
+// Left recursive rules are parsed by growing a seed: the rule is first
+// parsed with its recursive reference failing, then parsed again with the
+// reference matching the previous result, until the match stops getting
+// longer. This makes left recursive rules left associative, so the example
+// above parses "1-2-3" as "(1-2)-3".
+//
+// A left recursive rule must also be entered through LeftRec, as when
+// parsing with Parse(comb.LeftRec(&expr), s), so that it can grow.
+// Each rule in a cycle of indirectly left recursive rules should be
+// referred to with LeftRec. Results of a rule are memoized as with Memo.
+func LeftRec(p *Parser) Parser {
+	return leftRec(leftRecID{p: p}, func() Parser {
+		return *p
+	})
+}
+
+// leftRec builds a LeftRec parser of the rule returned by rule, which is
+// memoized under id.
+func leftRec(id leftRecID, rule func() Parser) Parser {
+	return leftRecParser{id: id, rule: rule}
+}
+
+type leftRecParser struct {
+	id   leftRecID
+	rule func() Parser
+}
+
+func (lr leftRecParser) Parse(s Scanner) (Result, Scanner) {
+	if s.st == nil {
+		return Parse(lr, s)
+	}
+
+	if r, ok := s.st.enter(s); !ok {
+		return r, s
+	}
+
+	r, next := s.st.leftRec(lr.id, lr.rule(), s)
+	s.st.leave()
+	return r, next
+}
+
+func (lr leftRecParser) parserName() string {
+	return parserName(lr.rule())
+}
+
+// leftRecID identifies a rule in the memo table, separately from its
+// memoized References, by the pointer to its Parser or TypedParser.
+type leftRecID struct {
+	p interface{}
+}
+
+// leftRec parses a possibly left recursive rule p at s, growing the seed.
+func (st *state) leftRec(id interface{}, p Parser, s Scanner) (Result, Scanner) {
+	key := memoKey{id: id, offset: s.i}
+
+	if e, ok := st.memos[key]; ok {
+		if e.failure != nil {
+			st.record(e.failure)
+		}
+		st.errors = append(st.errors, e.errors...)
+		return e.r, e.next
+	}
+
+	if st.memos == nil {
+		st.memos = make(map[memoKey]memoEntry)
+	}
+	if st.heads == nil {
+		st.heads = make(map[int]int)
+	}
+
+	// If another rule is growing here, this one's results depend on that
+	// rule's current seed, and must not be kept once this call returns.
+	involved := st.heads[s.i] > 0
+	st.heads[s.i]++
+
+	mark := st.begin()
+	pin := st.pin(s)
+
+	e := memoEntry{
+		r:    Failed(&ParseError{Scanner: s}),
+		next: s,
+	}
+	st.memos[key] = e
+
+	for {
+		n := len(st.errors)
+
+		r, next := p.Parse(s)
+		if !r.Matched() {
+			if r.Committed || !e.r.Matched() {
+				e.r, e.next = r, next
+			} else {
+				st.record(r.Err)
+				st.errors = st.errors[:n]
+			}
+			break
+		}
+
+		if e.r.Matched() && next.i <= e.next.i {
+			st.errors = st.errors[:n]
+			break
+		}
+
+		// The errors recovered while parsing the previous seed are
+		// replaced by those of the new one.
+		st.errors = append(st.errors[:mark.errors], st.errors[n:]...)
+
+		e.r, e.next = r, next
+		st.memos[key] = e
+	}
+
+	st.heads[s.i]--
+	st.unpin(pin)
+
+	e.failure = st.furthest
+	e.errors = st.recovered(mark)
+	st.end(mark, nil)
+
+	if involved {
+		delete(st.memos, key)
+	} else {
+		st.memos[key] = e
+	}
+
+	return e.r, e.next
+}
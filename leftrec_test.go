@@ -0,0 +1,169 @@
+package comb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// parenthesize combines the results of a binary expression as "(a op b)".
+func parenthesize(results []Result, begin, end Scanner) Result {
+	var runes []rune
+	runes = append(runes, '(')
+	for _, r := range results {
+		runes = append(runes, r.Runes...)
+	}
+	runes = append(runes, ')')
+	return Result{Runes: runes}
+}
+
+func TestLeftRec(t *testing.T) {
+	digit := CharRange('0', '9')
+
+	var expr Parser
+	expr = Or(
+		Sequence(parenthesize, LeftRec(&expr), Char('-'), digit),
+		digit,
+	)
+	rule := LeftRec(&expr)
+
+	t.Run("left associative", func(t *testing.T) {
+		r, next := Parse(rule, NewStringScanner("1-2-3"))
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+		assert.Equal(t, "((1-2)-3)", string(r.Runes))
+		assert.Equal(t, lineSpan(0, 5), r.Span)
+	})
+
+	t.Run("base", func(t *testing.T) {
+		r, next := Parse(rule, NewStringScanner("1"))
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+		assert.Equal(t, "1", string(r.Runes))
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		r, next := Parse(rule, NewStringScanner("1-2-"))
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, "(1-2)", string(r.Runes))
+		assert.Equal(t, 3, next.Offset())
+	})
+
+	t.Run("without Parse", func(t *testing.T) {
+		r, next := rule.Parse(NewStringScanner("1-2-3"))
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+		assert.Equal(t, "((1-2)-3)", string(r.Runes))
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		p := Sequence(nil, rule, EOF())
+
+		r, _ := Parse(p, NewStringScanner("1-2+"))
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 4: expected one of '-', EOF but found '+'")
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		r, _ := Parse(rule, NewStringScanner("x"))
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 1: expected '0'-'9' but found 'x'")
+	})
+
+	t.Run("MemoizeReferences", func(t *testing.T) {
+		r, next := Parse(rule, NewStringScanner("1-2-3"), MemoizeReferences())
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+		assert.Equal(t, "((1-2)-3)", string(r.Runes))
+	})
+
+	t.Run("indirect", func(t *testing.T) {
+		var sum, term Parser
+		sum = Or(
+			Sequence(parenthesize, LeftRec(&term), Char('+'), digit),
+			digit,
+		)
+		term = Or(
+			Sequence(parenthesize, LeftRec(&sum), Char('*'), digit),
+			LeftRec(&sum),
+		)
+
+		r, next := Parse(LeftRec(&term), NewStringScanner("1+2*3+4"), MemoizeReferences())
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+		assert.Equal(t, "(((1+2)*3)+4)", string(r.Runes))
+	})
+}
+
+func TestLeftRecOf(t *testing.T) {
+	t.Run("left associative", func(t *testing.T) {
+		var expr TypedParser[int]
+		expr = OrOf(
+			Seq3(LeftRecOf(&expr), Text(Char('-')), typedNumber, func(a int, _ string, b int) int {
+				return a - b
+			}),
+			typedNumber,
+		)
+
+		v, r, next := LeftRecOf(&expr).ParseValue(NewStringScanner("10-2-3"))
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+		assert.Equal(t, 5, v)
+	})
+
+	t.Run("nil interface", func(t *testing.T) {
+		var expr TypedParser[error]
+		expr = Map(Text(Char('a')), func(string) error { return nil })
+
+		v, r, _ := LeftRecOf(&expr).ParseValue(NewStringScanner("a"))
+
+		assert.True(t, r.Matched())
+		assert.Nil(t, v)
+	})
+
+	t.Run("shared memo", func(t *testing.T) {
+		calls := 0
+
+		var expr TypedParser[string]
+		expr = TypedParserFunc(func(s Scanner) (string, Result, Scanner) {
+			calls++
+			return Text(Char('a')).ParseValue(s)
+		})
+
+		// Both references are to the same rule at the same offset, so the
+		// second is memoized.
+		p := Or(Sequence(nil, LeftRecOf(&expr), Char('b')), LeftRecOf(&expr))
+
+		r, _ := Parse(p, NewStringScanner("a"))
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func BenchmarkLeftRec(b *testing.B) {
+	digit := CharRange('0', '9')
+
+	var expr Parser
+	expr = Or(
+		Sequence(nil, LeftRec(&expr), Char('-'), digit),
+		digit,
+	)
+
+	rule := LeftRec(&expr)
+	input := "1-2-3-4-5-6-7-8-9"
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Parse(rule, NewStringScanner(input))
+	}
+}
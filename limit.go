@@ -0,0 +1,148 @@
+package comb
+
+import "fmt"
+
+// Limit is a limit on the resources used by a parse.
+type Limit int
+
+const (
+	// DepthLimit limits the depth of recursion, as set by MaxDepth.
+	DepthLimit Limit = iota + 1
+	// StepLimit limits the number of parsers invoked, as set by MaxSteps.
+	StepLimit
+	// InputSizeLimit limits the size of the input, as set by MaxInputSize.
+	InputSizeLimit
+)
+
+// String returns a description of the limit.
+func (l Limit) String() string {
+	switch l {
+	case DepthLimit:
+		return "recursion depth"
+	case StepLimit:
+		return "step"
+	case InputSizeLimit:
+		return "input size"
+	}
+	return fmt.Sprintf("Limit(%d)", int(l))
+}
+
+// LimitError is the cause of the failure of a parse which exceeded one
+// of its limits. Once a limit is exceeded, the parse stops, and every
+// parser fails with a committed *ParseError wrapping the LimitError, so
+// it can be found with errors.As.
+type LimitError struct {
+	Limit Limit
+	Max   int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s limit of %d exceeded", e.Limit, e.Max)
+}
+
+// MaxDepth limits the depth of recursion through Reference, ReferenceOf,
+// and LeftRec to n, so that deeply nested input cannot exhaust the stack.
+func MaxDepth(n int) Option {
+	return func(st *state) {
+		st.maxDepth = n
+	}
+}
+
+// MaxSteps limits the number of steps taken by a parse to n, so that
+// input which causes heavy backtracking cannot take too long. A step is
+// taken each time a reference is followed, an alternative of Or or
+// OrLongest is tried, or a repetition is attempted.
+func MaxSteps(n int) Option {
+	return func(st *state) {
+		st.maxSteps = n
+	}
+}
+
+// MaxInputSize limits the size of the input to n, in the same units as
+// Scanner.Offset. For scanners created by NewReaderScanner, the size of
+// the input is unknown, so the parse fails once it reads past the limit,
+// and no more than n+1 runes are read. Otherwise, the parse fails before
+// it starts.
+func MaxInputSize(n int) Option {
+	return func(st *state) {
+		st.maxInputSize = n
+	}
+}
+
+// step counts a step of the parse at s. If the parse must stop, the failed
+// result is returned with false.
+func (st *state) step(s Scanner) (Result, bool) {
+	if st == nil {
+		return Result{}, true
+	}
+
+	if st.stopped != nil {
+		return Result{Err: st.stopped, Committed: true}, false
+	}
+
+	st.steps++
+	if st.ctx != nil && st.steps%contextCheckInterval == 0 {
+		if st.checkContext(s); st.stopped != nil {
+			return Result{Err: st.stopped, Committed: true}, false
+		}
+	}
+
+	if st.maxSteps > 0 && st.steps > st.maxSteps {
+		return st.stop(s, &LimitError{Limit: StepLimit, Max: st.maxSteps}), false
+	}
+
+	return Result{}, true
+}
+
+// enter takes a step into a recursive parser at s, which must be followed
+// by a call to leave if it succeeds.
+func (st *state) enter(s Scanner) (Result, bool) {
+	if st == nil {
+		return Result{}, true
+	}
+
+	if r, ok := st.step(s); !ok {
+		return r, false
+	}
+
+	if st.maxDepth > 0 && st.depth >= st.maxDepth {
+		return st.stop(s, &LimitError{Limit: DepthLimit, Max: st.maxDepth}), false
+	}
+
+	st.depth++
+	return Result{}, true
+}
+
+// leave returns from a recursive parser.
+func (st *state) leave() {
+	if st != nil {
+		st.depth--
+	}
+}
+
+// stop stops the parse at s because of err, returning the failure
+// which will be returned by every later step.
+func (st *state) stop(s Scanner, err error) Result {
+	st.stopped = &ParseError{
+		Scanner: s,
+		Cause:   err,
+	}
+	return Result{Err: st.stopped, Committed: true}
+}
+
+// checkInputSize stops the parse before it starts at s if the input
+// is known to be too large. Streams are limited as they are read instead.
+func (st *state) checkInputSize(s Scanner) {
+	if st.maxInputSize <= 0 || s.stream != nil {
+		return
+	}
+
+	size := len(s.runes) - s.i
+	if s.text != "" {
+		size = len(s.text) - s.i
+	}
+
+	if size > st.maxInputSize {
+		st.stop(s, &LimitError{Limit: InputSizeLimit, Max: st.maxInputSize})
+	}
+}
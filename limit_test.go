@@ -0,0 +1,160 @@
+package comb
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func nested() Parser {
+	var expr Parser
+	expr = Or(
+		Surround(Char('('), Reference(&expr), Char(')')),
+		Char('x'),
+	)
+	return expr
+}
+
+func TestMaxDepth(t *testing.T) {
+	p := nested()
+
+	t.Run("within", func(t *testing.T) {
+		r, next := Parse(p, NewStringScanner("((x))"), MaxDepth(2))
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+	})
+
+	t.Run("exceeded", func(t *testing.T) {
+		r, _ := Parse(p, NewStringScanner("(((x)))"), MaxDepth(2))
+
+		var le *LimitError
+		assert.False(t, r.Matched())
+		assert.True(t, r.Committed)
+		assert.True(t, errors.As(r.Err, &le))
+		assert.Equal(t, &LimitError{Limit: DepthLimit, Max: 2}, le)
+		assert.EqualError(t, r.Err, "line 1 col 4: recursion depth limit of 2 exceeded")
+
+		var pe *ParseError
+		assert.True(t, errors.As(r.Err, &pe))
+		assert.Equal(t, 3, pe.Offset())
+	})
+
+	t.Run("deep", func(t *testing.T) {
+		input := strings.Repeat("(", 1000000)
+
+		r, _ := Parse(p, NewStringScanner(input), MaxDepth(1000))
+
+		var le *LimitError
+		assert.True(t, errors.As(r.Err, &le))
+		assert.Equal(t, DepthLimit, le.Limit)
+	})
+
+	t.Run("typed", func(t *testing.T) {
+		var expr TypedParser[int]
+		expr = OrOf(
+			Seq3(Text(Char('(')), ReferenceOf(&expr), Text(Char(')')), func(_ string, n int, _ string) int {
+				return n + 1
+			}),
+			Pure(0),
+		)
+
+		r, _ := Parse(expr, NewStringScanner("((()))"), MaxDepth(2))
+
+		var le *LimitError
+		assert.True(t, errors.As(r.Err, &le))
+		assert.Equal(t, DepthLimit, le.Limit)
+	})
+}
+
+func TestMaxSteps(t *testing.T) {
+	p := backtracking(func(p Parser) Parser { return p })
+	input := strings.Repeat("(", 12) + "x" + strings.Repeat(")", 12)
+
+	r, _ := Parse(p, NewStringScanner(input), MaxSteps(1000))
+
+	var le *LimitError
+	assert.False(t, r.Matched())
+	assert.True(t, errors.As(r.Err, &le))
+	assert.Equal(t, &LimitError{Limit: StepLimit, Max: 1000}, le)
+
+	r, _ = Parse(backtracking(Memo), NewStringScanner(input), MaxSteps(1000))
+
+	assert.False(t, errors.As(r.Err, &le))
+}
+
+func TestMaxInputSize(t *testing.T) {
+	p := ManyRunes(Char('a'))
+
+	t.Run("within", func(t *testing.T) {
+		r, _ := Parse(p, NewUTF8Scanner("aaaa"), MaxInputSize(4))
+
+		assert.True(t, r.Matched())
+	})
+
+	t.Run("exceeded", func(t *testing.T) {
+		r, next := Parse(p, NewUTF8Scanner("aaaaa"), MaxInputSize(4))
+
+		var le *LimitError
+		assert.False(t, r.Matched())
+		assert.True(t, errors.As(r.Err, &le))
+		assert.Equal(t, &LimitError{Limit: InputSizeLimit, Max: 4}, le)
+		assert.Equal(t, 0, next.Offset())
+	})
+
+	t.Run("reader", func(t *testing.T) {
+		rr := strings.NewReader(strings.Repeat("a", 100))
+		s := NewReaderScanner(rr)
+
+		r, _ := Parse(Sequence(nil, Regexp("a*"), EOF()), s, MaxInputSize(10))
+
+		var le *LimitError
+		assert.False(t, r.Matched())
+		assert.True(t, errors.As(r.Err, &le))
+		assert.Equal(t, &LimitError{Limit: InputSizeLimit, Max: 10}, le)
+		assert.Equal(t, 10, r.Err.(*ParseError).Offset())
+		assert.Equal(t, 89, rr.Len())
+	})
+
+	t.Run("reader within", func(t *testing.T) {
+		s := NewReaderScanner(strings.NewReader("aaaa"))
+
+		r, _ := Parse(Sequence(nil, p, EOF()), s, MaxInputSize(4))
+
+		assert.True(t, r.Matched())
+	})
+
+	t.Run("reader reused", func(t *testing.T) {
+		s := NewReaderScanner(strings.NewReader("aaaaaaaa"))
+
+		r, next := Parse(Sequence(nil, Char('a'), Char('a')), s, MaxInputSize(2))
+		assert.True(t, r.Matched())
+
+		r, _ = Parse(p, next, MaxInputSize(6))
+		assert.True(t, r.Matched())
+
+		r, next = p.Parse(next)
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+	})
+}
+
+func TestLimitRecover(t *testing.T) {
+	p := Many(nil, Recover(nested(), Char(';')))
+
+	r, _, errs := ParseAll(p, NewStringScanner("(((x)));x;"), MaxDepth(2))
+
+	var le *LimitError
+	assert.False(t, r.Matched())
+	assert.True(t, errors.As(r.Err, &le))
+	assert.Len(t, errs, 1)
+}
+
+func TestLimitString(t *testing.T) {
+	assert.Equal(t, "recursion depth", DepthLimit.String())
+	assert.Equal(t, "step", StepLimit.String())
+	assert.Equal(t, "input size", InputSizeLimit.String())
+	assert.Equal(t, "Limit(0)", Limit(0).String())
+}
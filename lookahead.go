@@ -0,0 +1,60 @@
+package comb
+
+import "sync"
+
+// Peek runs a parser without consuming any input. If the parser matches,
+// Peek matches with an empty result and the original scanner. Otherwise,
+// the parser's failure is returned.
+func Peek(parser Parser) Parser {
+	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		pin := s.st.pin(s)
+		r, next := parser.Parse(s)
+		s.st.unpin(pin)
+		if !r.Matched() {
+			return r, next
+		}
+
+		return Result{Span: s.Span(s)}, s
+	})
+}
+
+// And is an alias for Peek, named after the PEG and-predicate.
+func And(parser Parser) Parser {
+	return Peek(parser)
+}
+
+// Not runs a parser without consuming any input, matching only if the
+// parser does not match. Not matches with an empty result and the original
+// scanner. Failures of the parser are not reported. If the parser is named
+// by Label or Tag, even through a Reference, the failure of Not expects
+// "not " followed by the name.
+//
+// Not can be used to make sure that a keyword is not the start of a longer
+// identifier:
+//
+//	ifKeyword := comb.Sequence(nil, comb.Token("if"), comb.Not(identChar))
+func Not(parser Parser) Parser {
+	// The name is found on first use, as a Reference may not be set yet
+	// when Not is called.
+	var once sync.Once
+	var expected []string
+
+	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		mark := s.st.begin()
+		pin := s.st.pin(s)
+		r, _ := parser.Parse(s)
+		s.st.unpin(pin)
+		s.st.discard(mark)
+
+		if r.Matched() {
+			once.Do(func() {
+				if name := parserName(parser); name != "" {
+					expected = []string{"not " + name}
+				}
+			})
+			return FailedAt(s, expected...), s
+		}
+
+		return Result{Span: s.Span(s)}, s
+	})
+}
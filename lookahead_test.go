@@ -0,0 +1,92 @@
+package comb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeek(t *testing.T) {
+	p := Peek(Token("if"))
+
+	t.Run("match", func(t *testing.T) {
+		s := NewStringScanner("if")
+
+		r, next := p.Parse(s)
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, Result{Span: lineSpan(0, 0)}, r)
+		assert.Equal(t, 0, next.Offset())
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		s := NewStringScanner("else")
+
+		r, _ := And(Token("if")).Parse(s)
+
+		assert.False(t, r.Matched())
+	})
+}
+
+func TestNot(t *testing.T) {
+	ident := CharRange('a', 'z')
+	keyword := Sequence(nil, Token("if"), Not(ident))
+
+	t.Run("match", func(t *testing.T) {
+		s := NewStringScanner("if x")
+
+		r, next := Parse(keyword, s)
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, 2, next.Offset())
+	})
+
+	t.Run("EOF", func(t *testing.T) {
+		s := NewStringScanner("if")
+
+		r, next := Parse(keyword, s)
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		s := NewStringScanner("iffy")
+
+		r, next := Parse(keyword, s)
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 3: unexpected 'f'")
+		assert.Equal(t, 2, next.Offset())
+	})
+
+	t.Run("labelled", func(t *testing.T) {
+		keyword := Sequence(nil, Token("if"), Not(Label("identifier character", ident)))
+		s := NewStringScanner("iffy")
+
+		r, _ := Parse(keyword, s)
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 3: expected not identifier character but found 'f'")
+	})
+
+	t.Run("referenced label", func(t *testing.T) {
+		var identChar Parser
+		keyword := Sequence(nil, Token("if"), Not(Reference(&identChar)))
+		identChar = Label("identifier character", ident)
+
+		r, _ := Parse(keyword, NewStringScanner("iffy"))
+
+		assert.EqualError(t, r.Err, "line 1 col 3: expected not identifier character but found 'f'")
+	})
+
+	t.Run("not reported", func(t *testing.T) {
+		p := Sequence(nil, keyword, Char(';'))
+		s := NewStringScanner("if?")
+
+		r, _ := Parse(p, s)
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 3: expected ';' but found '?'")
+	})
+}
@@ -1,30 +1,58 @@
 package comb
 
+import "fmt"
+
 // Many looks for a series of 0+ matches of a parser,
 // then combines the results with a combiner. If combiner is nil,
 // SliceCombiner is used.
 //
+// If the parser matches without consuming any input, such as Maybe(p)
+// when p does not match, the repetition stops rather than looping forever,
+// and the empty match is not included. Building with the combdebug build
+// tag makes this panic instead, to help find such parsers in a grammar.
+// The same applies to the other unbounded repetitions.
+//
 // If you only need the runes captured by Many, use TextMany instead.
 func Many(combiner ResultCombiner, parser Parser) Parser {
+	pinned := combiner != nil
 	if combiner == nil {
 		combiner = SliceCombiner
 	}
 
 	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		pin := s.st.pin(s)
 		var results []Result
 		next := s
 
 		for {
+			if r, ok := s.st.step(next); !ok {
+				s.st.unpin(pin)
+				return r, next
+			}
+
 			r, maybeNext := parser.Parse(next)
+			if r.Committed {
+				s.st.unpin(pin)
+				return r, maybeNext
+			}
 			if !r.Matched() {
+				s.st.record(r.Err)
+				break
+			}
+			if stalled(parser, next, maybeNext) {
 				break
 			}
 
 			next = maybeNext
 			results = append(results, r)
+			if !pinned {
+				s.st.repin(pin, next)
+			}
 		}
 
-		return combiner(results, s, next), next
+		r := combine(combiner, results, s, next)
+		s.st.unpin(pin)
+		return r, next
 	})
 }
 
@@ -32,19 +60,32 @@ func Many(combiner ResultCombiner, parser Parser) Parser {
 // then returns the runes captured.
 func ManyRunes(parser Parser) Parser {
 	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		pin := s.st.pin(s)
 		next := s
 
 		for {
+			if r, ok := s.st.step(next); !ok {
+				s.st.unpin(pin)
+				return r, next
+			}
+
 			r, maybeNext := parser.Parse(next)
+			if r.Committed {
+				s.st.unpin(pin)
+				return r, maybeNext
+			}
 			if !r.Matched() {
+				s.st.record(r.Err)
+				break
+			}
+			if stalled(parser, next, maybeNext) {
 				break
 			}
 			next = maybeNext
 		}
 
-		return Result{
-			Runes: s.Between(next),
-		}, next
+		s.st.unpin(pin)
+		return s.Result(next), next
 	})
 }
 
@@ -52,21 +93,42 @@ func ManyRunes(parser Parser) Parser {
 //
 // If you only need the runes captured by OnePlus, use TextOnePlus instead.
 func OnePlus(combiner ResultCombiner, parser Parser) Parser {
+	pinned := combiner != nil
 	if combiner == nil {
 		combiner = SliceCombiner
 	}
 
 	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		pin := s.st.pin(s)
+
 		r, next := parser.Parse(s)
 		if !r.Matched() {
+			s.st.unpin(pin)
 			return r, next
 		}
 
 		results := []Result{r}
 
 		for {
+			if !pinned {
+				s.st.repin(pin, next)
+			}
+
+			if r, ok := s.st.step(next); !ok {
+				s.st.unpin(pin)
+				return r, next
+			}
+
 			r, maybeNext := parser.Parse(next)
+			if r.Committed {
+				s.st.unpin(pin)
+				return r, maybeNext
+			}
 			if !r.Matched() {
+				s.st.record(r.Err)
+				break
+			}
+			if stalled(parser, next, maybeNext) {
 				break
 			}
 
@@ -74,7 +136,9 @@ func OnePlus(combiner ResultCombiner, parser Parser) Parser {
 			results = append(results, r)
 		}
 
-		return combiner(results, s, next), next
+		r = combine(combiner, results, s, next)
+		s.st.unpin(pin)
+		return r, next
 	})
 }
 
@@ -82,21 +146,478 @@ func OnePlus(combiner ResultCombiner, parser Parser) Parser {
 // then returns the runes captured.
 func OnePlusRunes(parser Parser) Parser {
 	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		pin := s.st.pin(s)
+
 		r, next := parser.Parse(s)
 		if !r.Matched() {
+			s.st.unpin(pin)
 			return r, next
 		}
 
 		for {
+			if r, ok := s.st.step(next); !ok {
+				s.st.unpin(pin)
+				return r, next
+			}
+
 			r, maybeNext := parser.Parse(next)
+			if r.Committed {
+				s.st.unpin(pin)
+				return r, maybeNext
+			}
+			if !r.Matched() {
+				s.st.record(r.Err)
+				break
+			}
+			if stalled(parser, next, maybeNext) {
+				break
+			}
+			next = maybeNext
+		}
+
+		s.st.unpin(pin)
+		return s.Result(next), next
+	})
+}
+
+// SepBy looks for a series of 0+ matches of a parser, separated by
+// matches of sep, then combines the results of parser with a combiner.
+// The results of sep are not included. If combiner is nil,
+// SliceCombiner is used.
+//
+// If sep matches but parser does not match after it, the separator is
+// left unparsed.
+//
+//	list := comb.SepBy(nil, item, comb.Char(','))
+func SepBy(combiner ResultCombiner, parser, sep Parser) Parser {
+	return separatedBy(combiner, parser, sep, sepBetween, 0)
+}
+
+// SepByRunes is like SepBy, but returns the runes captured,
+// including the separators.
+func SepByRunes(parser, sep Parser) Parser {
+	return separatedByRunes(parser, sep, sepBetween, 0)
+}
+
+// SepBy1 is like SepBy, but requires at least one match.
+func SepBy1(combiner ResultCombiner, parser, sep Parser) Parser {
+	return separatedBy(combiner, parser, sep, sepBetween, 1)
+}
+
+// SepBy1Runes is like SepByRunes, but requires at least one match.
+func SepBy1Runes(parser, sep Parser) Parser {
+	return separatedByRunes(parser, sep, sepBetween, 1)
+}
+
+// EndBy looks for a series of 0+ matches of a parser, each followed by
+// a match of sep, such as statements ending in semicolons. The results
+// of parser are combined with a combiner. If combiner is nil,
+// SliceCombiner is used.
+func EndBy(combiner ResultCombiner, parser, sep Parser) Parser {
+	return separatedBy(combiner, parser, sep, sepAfter, 0)
+}
+
+// EndByRunes is like EndBy, but returns the runes captured,
+// including the separators.
+func EndByRunes(parser, sep Parser) Parser {
+	return separatedByRunes(parser, sep, sepAfter, 0)
+}
+
+// SepEndBy is like SepBy, but allows a trailing separator after the
+// last match, which is consumed.
+func SepEndBy(combiner ResultCombiner, parser, sep Parser) Parser {
+	return separatedBy(combiner, parser, sep, sepOptionalAfter, 0)
+}
+
+// SepEndByRunes is like SepByRunes, but allows a trailing separator
+// after the last match, which is consumed.
+func SepEndByRunes(parser, sep Parser) Parser {
+	return separatedByRunes(parser, sep, sepOptionalAfter, 0)
+}
+
+// sepMode describes where separators appear in a separated list.
+type sepMode int
+
+const (
+	// sepBetween requires separators between matches.
+	sepBetween sepMode = iota
+	// sepAfter requires a separator after every match.
+	sepAfter
+	// sepOptionalAfter requires separators between matches, and allows
+	// one after the last.
+	sepOptionalAfter
+)
+
+func separatedBy(combiner ResultCombiner, parser, sep Parser, mode sepMode, min int) Parser {
+	pinned := combiner != nil
+	if combiner == nil {
+		combiner = SliceCombiner
+	}
+
+	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		results, r, next := separated(s, parser, sep, mode, min, true, pinned)
+		if !r.Matched() {
+			return r, next
+		}
+
+		return combine(combiner, results, s, next), next
+	})
+}
+
+func separatedByRunes(parser, sep Parser, mode sepMode, min int) Parser {
+	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		_, r, next := separated(s, parser, sep, mode, min, false, true)
+		if !r.Matched() {
+			return r, next
+		}
+
+		return s.Result(next), next
+	})
+}
+
+// separated parses a list of matches of parser separated by matches of sep.
+// If keep is true, the results of parser are returned. If pinned is true,
+// the input of the whole list is kept buffered, rather than only that of
+// the match being tried. If the list could not be parsed, the failed
+// result is returned.
+func separated(s Scanner, parser, sep Parser, mode sepMode, min int, keep, pinned bool) ([]Result, Result, Scanner) {
+	pin := s.st.pin(s)
+	var results []Result
+	next := s
+
+	for n := 0; ; n++ {
+		if r, ok := s.st.step(next); !ok {
+			s.st.unpin(pin)
+			return nil, r, next
+		}
+
+		start, item := next, next
+
+		if n > 0 && mode != sepAfter {
+			r, sepNext := sep.Parse(next)
+			if r.Committed {
+				s.st.unpin(pin)
+				return nil, r, sepNext
+			}
 			if !r.Matched() {
+				s.st.record(r.Err)
+				break
+			}
+
+			item = sepNext
+			if mode == sepOptionalAfter {
+				next = sepNext
+			}
+		}
+
+		r, itemNext := parser.Parse(item)
+		if r.Committed || !r.Matched() && n < min {
+			s.st.unpin(pin)
+			return nil, r, itemNext
+		}
+		if !r.Matched() {
+			s.st.record(r.Err)
+			break
+		}
+
+		if mode == sepAfter {
+			sr, sepNext := sep.Parse(itemNext)
+			if sr.Committed {
+				s.st.unpin(pin)
+				return nil, sr, sepNext
+			}
+			if !sr.Matched() {
+				s.st.record(sr.Err)
 				break
 			}
+			itemNext = sepNext
+		}
+
+		// The first item may be empty, as it is not preceded by a separator,
+		// but every later iteration, separator included, must consume input.
+		if (n > 0 || mode == sepAfter) && stalled(parser, start, itemNext) {
+			break
+		}
+
+		if keep {
+			results = append(results, r)
+		}
+		next = itemNext
+		if !pinned {
+			s.st.repin(pin, next)
+		}
+	}
+
+	s.st.unpin(pin)
+	return results, Result{}, next
+}
+
+// Repeat looks for between min and max matches of a parser, then combines
+// the results with a combiner. If max is negative, there is no upper bound.
+// Once max matches have been found, parser is not run again. If combiner
+// is nil, SliceCombiner is used. Repeat panics if min is greater than a
+// non-negative max, as nothing could ever match.
+//
+//	hex4 := comb.Repeat(4, 4, nil, hexDigit)
+func Repeat(min, max int, combiner ResultCombiner, parser Parser) Parser {
+	checkRepeat(min, max)
+
+	pinned := combiner != nil
+	if combiner == nil {
+		combiner = SliceCombiner
+	}
+
+	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		results, r, next := repeat(s, parser, min, max, true, pinned)
+		if !r.Matched() {
+			return r, next
+		}
+
+		return combine(combiner, results, s, next), next
+	})
+}
+
+// RepeatRunes is like Repeat, but returns the runes captured.
+func RepeatRunes(min, max int, parser Parser) Parser {
+	checkRepeat(min, max)
+
+	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		_, r, next := repeat(s, parser, min, max, false, true)
+		if !r.Matched() {
+			return r, next
+		}
+
+		return s.Result(next), next
+	})
+}
+
+func checkRepeat(min, max int) {
+	if max >= 0 && min > max {
+		panic(fmt.Sprintf("comb: repeat minimum %d is greater than maximum %d", min, max))
+	}
+}
+
+// Count looks for exactly n matches of a parser.
+func Count(n int, combiner ResultCombiner, parser Parser) Parser {
+	return Repeat(n, n, combiner, parser)
+}
+
+// CountRunes looks for exactly n matches of a parser,
+// then returns the runes captured.
+func CountRunes(n int, parser Parser) Parser {
+	return RepeatRunes(n, n, parser)
+}
+
+// AtLeast looks for a series of n+ matches of a parser.
+func AtLeast(n int, combiner ResultCombiner, parser Parser) Parser {
+	return Repeat(n, -1, combiner, parser)
+}
+
+// AtLeastRunes looks for a series of n+ matches of a parser,
+// then returns the runes captured.
+func AtLeastRunes(n int, parser Parser) Parser {
+	return RepeatRunes(n, -1, parser)
+}
+
+// AtMost looks for up to n matches of a parser.
+func AtMost(n int, combiner ResultCombiner, parser Parser) Parser {
+	return Repeat(0, n, combiner, parser)
+}
+
+// AtMostRunes looks for up to n matches of a parser,
+// then returns the runes captured.
+func AtMostRunes(n int, parser Parser) Parser {
+	return RepeatRunes(0, n, parser)
+}
+
+// repeat parses between min and max matches of parser. If keep is true,
+// the results are returned. If pinned is true, the input of every match
+// is kept buffered, rather than only that of the match being tried. If
+// fewer than min matches were found, the failed result is returned.
+func repeat(s Scanner, parser Parser, min, max int, keep, pinned bool) ([]Result, Result, Scanner) {
+	pin := s.st.pin(s)
+	var results []Result
+	next := s
+
+	for n := 0; max < 0 || n < max; n++ {
+		if r, ok := s.st.step(next); !ok {
+			s.st.unpin(pin)
+			return nil, r, next
+		}
+
+		r, maybeNext := parser.Parse(next)
+		if r.Committed || !r.Matched() && n < min {
+			s.st.unpin(pin)
+			return nil, r, maybeNext
+		}
+		if !r.Matched() {
+			s.st.record(r.Err)
+			break
+		}
+		if max < 0 && stalled(parser, next, maybeNext) {
+			break
+		}
+
+		if keep {
+			results = append(results, r)
+		}
+		next = maybeNext
+		if !pinned {
+			s.st.repin(pin, next)
+		}
+	}
+
+	s.st.unpin(pin)
+	return results, Result{}, next
+}
+
+// ManyTill looks for a series of 0+ matches of a parser, ending with a
+// match of end, which is tried first at each position. The results of
+// parser, followed by the result of end, are combined with a combiner.
+// To leave out the result of end, wrap end in Ignore. If combiner is nil,
+// SliceCombiner is used.
+//
+// If end does not match, and parser does not match either, ManyTill fails.
+//
+//	comment := comb.ManyTill(nil, comb.Take(1), comb.Ignore(comb.Token("*/")))
+func ManyTill(combiner ResultCombiner, parser, end Parser) Parser {
+	pinned := combiner != nil
+	if combiner == nil {
+		combiner = SliceCombiner
+	}
+
+	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		mark := s.st.begin()
+		pin := s.st.pin(s)
+		var results []Result
+		next := s
+
+		for {
+			if r, ok := s.st.step(next); !ok {
+				s.st.unpin(pin)
+				return r, next
+			}
+
+			r, endNext := end.Parse(next)
+			if r.Committed {
+				s.st.unpin(pin)
+				return s.st.fail(mark, r), endNext
+			}
+			if r.Matched() {
+				s.st.end(mark, nil)
+				results = append(results, r)
+				s.st.unpin(pin)
+				return combine(combiner, results, s, endNext), endNext
+			}
+			s.st.record(r.Err)
+
+			r, maybeNext := parser.Parse(next)
+			if !r.Matched() {
+				s.st.unpin(pin)
+				return s.st.fail(mark, r), maybeNext
+			}
+			if stalled(parser, next, maybeNext) {
+				s.st.unpin(pin)
+				return s.st.fail(mark, stalledFailure(parser, next)), next
+			}
+
+			next = maybeNext
+			results = append(results, r)
+			if !pinned {
+				s.st.repin(pin, next)
+			}
+		}
+	})
+}
+
+// ManyRunesTill is like ManyTill, but returns the runes captured by
+// the matches of parser. The input matched by end is consumed, but is not
+// included in the runes or the span of the result.
+func ManyRunesTill(parser, end Parser) Parser {
+	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		mark := s.st.begin()
+		pin := s.st.pin(s)
+		next := s
+
+		for {
+			if r, ok := s.st.step(next); !ok {
+				s.st.unpin(pin)
+				return r, next
+			}
+
+			r, endNext := end.Parse(next)
+			if r.Committed {
+				s.st.unpin(pin)
+				return s.st.fail(mark, r), endNext
+			}
+			if r.Matched() {
+				s.st.end(mark, nil)
+				s.st.unpin(pin)
+				return s.Result(next), endNext
+			}
+			s.st.record(r.Err)
+
+			r, maybeNext := parser.Parse(next)
+			if !r.Matched() {
+				s.st.unpin(pin)
+				return s.st.fail(mark, r), maybeNext
+			}
+			if stalled(parser, next, maybeNext) {
+				s.st.unpin(pin)
+				return s.st.fail(mark, stalledFailure(parser, next)), next
+			}
+
 			next = maybeNext
 		}
+	})
+}
+
+// SkipUntil skips over any characters until end matches, returning
+// the result of end. If EOF is reached first, SkipUntil fails.
+func SkipUntil(end Parser) Parser {
+	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		pin := s.st.pin(s)
+		next := s
+
+		for {
+			if r, ok := s.st.step(next); !ok {
+				s.st.unpin(pin)
+				return r, next
+			}
+
+			r, endNext := end.Parse(next)
+			if r.Matched() || r.Committed {
+				s.st.unpin(pin)
+				return r, endNext
+			}
 
-		return Result{
-			Runes: s.Between(next),
-		}, next
+			var err error
+			if _, next, err = next.Next(); err != nil {
+				s.st.unpin(pin)
+				return r, endNext
+			}
+			s.st.repin(pin, next)
+		}
 	})
 }
+
+// stalled returns true if a match of parser from s to next did not consume
+// any input, in which case a repetition must stop to avoid looping forever.
+// In debug builds, it panics instead.
+func stalled(parser Parser, s, next Scanner) bool {
+	if next.i != s.i {
+		return false
+	}
+
+	if debug {
+		panic(fmt.Sprintf("comb: %s matched without consuming input at %s", describe(parser), s.position()))
+	}
+
+	return true
+}
+
+// stalledFailure is the failure of a repetition which cannot stop when
+// parser matches without consuming any input at s.
+func stalledFailure(parser Parser, s Scanner) Result {
+	return FailedAtf(s, nil, "%s matched without consuming input", describe(parser))
+}
@@ -1,6 +1,8 @@
 package comb
 
 import (
+	"errors"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -19,11 +21,12 @@ func TestMany(t *testing.T) {
 
 		expected := Result{
 			Interface: []Result{
-				{Runes: []rune("a")},
-				{Runes: []rune("a")},
-				{Runes: []rune("a")},
-				{Runes: []rune("a")},
+				{Runes: []rune("a"), Span: lineSpan(0, 1)},
+				{Runes: []rune("a"), Span: lineSpan(1, 2)},
+				{Runes: []rune("a"), Span: lineSpan(2, 3)},
+				{Runes: []rune("a"), Span: lineSpan(3, 4)},
 			},
+			Span: lineSpan(0, 4),
 		}
 
 		assert.True(t, r.Matched())
@@ -38,6 +41,7 @@ func TestMany(t *testing.T) {
 
 		expected := Result{
 			Interface: []Result(nil),
+			Span:      lineSpan(0, 0),
 		}
 
 		assert.True(t, r.Matched())
@@ -58,6 +62,7 @@ func TestManyRunes(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune("aaaa"),
+			Span:  lineSpan(0, 4),
 		}
 
 		assert.True(t, r.Matched())
@@ -72,6 +77,7 @@ func TestManyRunes(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune(""),
+			Span:  lineSpan(0, 0),
 		}
 
 		assert.True(t, r.Matched())
@@ -93,11 +99,12 @@ func TestOnePlus(t *testing.T) {
 
 		expected := Result{
 			Interface: []Result{
-				{Runes: []rune("a")},
-				{Runes: []rune("a")},
-				{Runes: []rune("a")},
-				{Runes: []rune("a")},
+				{Runes: []rune("a"), Span: lineSpan(0, 1)},
+				{Runes: []rune("a"), Span: lineSpan(1, 2)},
+				{Runes: []rune("a"), Span: lineSpan(2, 3)},
+				{Runes: []rune("a"), Span: lineSpan(3, 4)},
 			},
+			Span: lineSpan(0, 4),
 		}
 
 		assert.True(t, r.Matched())
@@ -111,7 +118,7 @@ func TestOnePlus(t *testing.T) {
 		r, next := p.Parse(s)
 
 		assert.False(t, r.Matched())
-		assert.EqualError(t, r.Err, "unexpected character 'b'")
+		assert.EqualError(t, r.Err, "line 1 col 1: expected 'a' but found 'b'")
 		assert.False(t, next.EOF())
 	})
 }
@@ -128,6 +135,7 @@ func TestOnePlusRunes(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune("aaaa"),
+			Span:  lineSpan(0, 4),
 		}
 
 		assert.True(t, r.Matched())
@@ -141,7 +149,491 @@ func TestOnePlusRunes(t *testing.T) {
 		r, next := p.Parse(s)
 
 		assert.False(t, r.Matched())
-		assert.EqualError(t, r.Err, "unexpected character 'b'")
+		assert.EqualError(t, r.Err, "line 1 col 1: expected 'a' but found 'b'")
 		assert.False(t, next.EOF())
 	})
 }
+
+func TestSepBy(t *testing.T) {
+	p := SepBy(
+		nil,
+		CharRange('a', 'z'),
+		Char(','),
+	)
+
+	t.Run("match", func(t *testing.T) {
+		s := NewStringScanner("a,b,c")
+
+		r, next := p.Parse(s)
+
+		expected := Result{
+			Interface: []Result{
+				{Runes: []rune("a"), Span: lineSpan(0, 1)},
+				{Runes: []rune("b"), Span: lineSpan(2, 3)},
+				{Runes: []rune("c"), Span: lineSpan(4, 5)},
+			},
+			Span: lineSpan(0, 5),
+		}
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, expected, r)
+		assert.True(t, next.EOF())
+	})
+
+	t.Run("trailing separator", func(t *testing.T) {
+		s := NewStringScanner("a,b,")
+
+		r, next := p.Parse(s)
+
+		assert.True(t, r.Matched())
+		assert.Len(t, r.Interface, 2)
+		assert.Equal(t, 3, next.Offset())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		s := NewStringScanner("1")
+
+		r, next := p.Parse(s)
+
+		expected := Result{
+			Interface: []Result(nil),
+			Span:      lineSpan(0, 0),
+		}
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, expected, r)
+		assert.Equal(t, 0, next.Offset())
+	})
+}
+
+func TestSepByEmptyItems(t *testing.T) {
+	p := SepBy(nil, ManyRunes(NotChar(',', '\n')), Char(','))
+
+	tests := []struct {
+		input string
+		items []string
+	}{
+		{",a,b", []string{"", "a", "b"}},
+		{"a,,b", []string{"a", "", "b"}},
+		{"a,b,", []string{"a", "b", ""}},
+		{"", []string{""}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			r, next := p.Parse(NewStringScanner(test.input))
+
+			var items []string
+			for _, item := range r.Interface.([]Result) {
+				items = append(items, item.AsString())
+			}
+
+			assert.True(t, r.Matched())
+			assert.Equal(t, test.items, items)
+			assert.True(t, next.EOF())
+		})
+	}
+}
+
+func TestSepByRunes(t *testing.T) {
+	p := SepByRunes(
+		CharRange('a', 'z'),
+		Char(','),
+	)
+
+	s := NewStringScanner("a,b,c,")
+
+	r, next := p.Parse(s)
+
+	expected := Result{
+		Runes: []rune("a,b,c"),
+		Span:  lineSpan(0, 5),
+	}
+
+	assert.True(t, r.Matched())
+	assert.Equal(t, expected, r)
+	assert.Equal(t, 5, next.Offset())
+}
+
+func TestSepBy1(t *testing.T) {
+	p := SepBy1(
+		nil,
+		CharRange('a', 'z'),
+		Char(','),
+	)
+
+	t.Run("match", func(t *testing.T) {
+		s := NewStringScanner("a,b")
+
+		r, next := p.Parse(s)
+
+		assert.True(t, r.Matched())
+		assert.Len(t, r.Interface, 2)
+		assert.True(t, next.EOF())
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		s := NewStringScanner(",a")
+
+		r, _ := p.Parse(s)
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 1: expected 'a'-'z' but found ','")
+	})
+
+	t.Run("runes", func(t *testing.T) {
+		r, _ := SepBy1Runes(CharRange('a', 'z'), Char(',')).Parse(NewStringScanner("1"))
+
+		assert.False(t, r.Matched())
+	})
+}
+
+func TestEndBy(t *testing.T) {
+	p := EndBy(
+		nil,
+		CharRange('a', 'z'),
+		Char(';'),
+	)
+
+	t.Run("match", func(t *testing.T) {
+		s := NewStringScanner("a;b;c")
+
+		r, next := p.Parse(s)
+
+		expected := Result{
+			Interface: []Result{
+				{Runes: []rune("a"), Span: lineSpan(0, 1)},
+				{Runes: []rune("b"), Span: lineSpan(2, 3)},
+			},
+			Span: lineSpan(0, 4),
+		}
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, expected, r)
+		assert.Equal(t, 4, next.Offset())
+	})
+
+	t.Run("runes", func(t *testing.T) {
+		r, next := EndByRunes(CharRange('a', 'z'), Char(';')).Parse(NewStringScanner("a;b;"))
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, "a;b;", string(r.Runes))
+		assert.True(t, next.EOF())
+	})
+}
+
+func TestSepEndBy(t *testing.T) {
+	p := SepEndBy(
+		nil,
+		CharRange('a', 'z'),
+		Char(','),
+	)
+
+	t.Run("trailing separator", func(t *testing.T) {
+		s := NewStringScanner("a,b,")
+
+		r, next := p.Parse(s)
+
+		assert.True(t, r.Matched())
+		assert.Len(t, r.Interface, 2)
+		assert.Equal(t, lineSpan(0, 4), r.Span)
+		assert.True(t, next.EOF())
+	})
+
+	t.Run("no trailing separator", func(t *testing.T) {
+		s := NewStringScanner("a,b")
+
+		r, next := p.Parse(s)
+
+		assert.True(t, r.Matched())
+		assert.Len(t, r.Interface, 2)
+		assert.True(t, next.EOF())
+	})
+
+	t.Run("runes", func(t *testing.T) {
+		r, next := SepEndByRunes(CharRange('a', 'z'), Char(',')).Parse(NewStringScanner("a,b,;"))
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, "a,b,", string(r.Runes))
+		assert.Equal(t, 4, next.Offset())
+	})
+}
+
+func TestSepByFailure(t *testing.T) {
+	p := Sequence(
+		nil,
+		Char('['),
+		SepBy(nil, CharRange('a', 'z'), Char(',')),
+		Char(']'),
+	)
+
+	r, _ := Parse(p, NewStringScanner("[a,b,]"))
+
+	var pe *ParseError
+	assert.False(t, r.Matched())
+	assert.True(t, errors.As(r.Err, &pe))
+	assert.Equal(t, 5, pe.Offset())
+	assert.Equal(t, []string{"'a'-'z'"}, pe.Expected)
+}
+
+func TestRepeat(t *testing.T) {
+	p := Repeat(
+		2,
+		3,
+		nil,
+		Char('a'),
+	)
+
+	t.Run("min", func(t *testing.T) {
+		s := NewStringScanner("aab")
+
+		r, next := p.Parse(s)
+
+		expected := Result{
+			Interface: []Result{
+				{Runes: []rune("a"), Span: lineSpan(0, 1)},
+				{Runes: []rune("a"), Span: lineSpan(1, 2)},
+			},
+			Span: lineSpan(0, 2),
+		}
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, expected, r)
+		assert.Equal(t, 2, next.Offset())
+	})
+
+	t.Run("max", func(t *testing.T) {
+		s := NewStringScanner("aaaaa")
+
+		r, next := p.Parse(s)
+
+		assert.True(t, r.Matched())
+		assert.Len(t, r.Interface, 3)
+		assert.Equal(t, 3, next.Offset())
+	})
+
+	t.Run("too few", func(t *testing.T) {
+		s := NewStringScanner("ab")
+
+		r, next := p.Parse(s)
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 2: expected 'a' but found 'b'")
+		assert.Equal(t, 1, next.Offset())
+	})
+}
+
+func TestBadRepeat(t *testing.T) {
+	assert.Panics(t, func() {
+		Repeat(3, 2, nil, Char('a'))
+	})
+	assert.Panics(t, func() {
+		RepeatRunes(1, 0, Char('a'))
+	})
+	assert.NotPanics(t, func() {
+		Repeat(3, -1, nil, Char('a'))
+	})
+}
+
+func TestRepeatRunes(t *testing.T) {
+	hex := Or(CharRange('0', '9'), CharRange('a', 'f'))
+
+	tests := []struct {
+		name    string
+		parser  Parser
+		input   string
+		matched bool
+		runes   string
+	}{
+		{"Count", CountRunes(4, hex), "00ff1", true, "00ff"},
+		{"Count short", CountRunes(4, hex), "0f", false, ""},
+		{"AtLeast", AtLeastRunes(2, hex), "0f0fg", true, "0f0f"},
+		{"AtLeast short", AtLeastRunes(2, hex), "0g", false, ""},
+		{"AtMost", AtMostRunes(2, hex), "0f0f", true, "0f"},
+		{"AtMost empty", AtMostRunes(2, hex), "g", true, ""},
+		{"Repeat", RepeatRunes(1, 3, hex), "ffg", true, "ff"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, _ := test.parser.Parse(NewStringScanner(test.input))
+
+			assert.Equal(t, test.matched, r.Matched())
+			if test.matched {
+				assert.Equal(t, test.runes, string(r.Runes))
+				assert.Equal(t, lineSpan(0, len(test.runes)), r.Span)
+			}
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	r, next := Count(2, nil, Char('a')).Parse(NewStringScanner("aaa"))
+
+	assert.True(t, r.Matched())
+	assert.Len(t, r.Interface, 2)
+	assert.Equal(t, 2, next.Offset())
+
+	r, _ = AtLeast(1, nil, Char('a')).Parse(NewStringScanner("aaa"))
+	assert.Len(t, r.Interface, 3)
+
+	r, _ = AtMost(1, nil, Char('a')).Parse(NewStringScanner("aaa"))
+	assert.Len(t, r.Interface, 1)
+}
+
+func TestManyTill(t *testing.T) {
+	p := ManyTill(
+		nil,
+		Char('a', 'b'),
+		Char('.'),
+	)
+
+	t.Run("match", func(t *testing.T) {
+		s := NewStringScanner("ab.c")
+
+		r, next := p.Parse(s)
+
+		expected := Result{
+			Interface: []Result{
+				{Runes: []rune("a"), Span: lineSpan(0, 1)},
+				{Runes: []rune("b"), Span: lineSpan(1, 2)},
+				{Runes: []rune("."), Span: lineSpan(2, 3)},
+			},
+			Span: lineSpan(0, 3),
+		}
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, expected, r)
+		assert.Equal(t, 3, next.Offset())
+	})
+
+	t.Run("ignored end", func(t *testing.T) {
+		p := ManyTill(nil, Char('a', 'b'), Ignore(Char('.')))
+		s := NewStringScanner("ab.")
+
+		r, next := p.Parse(s)
+
+		assert.True(t, r.Matched())
+		assert.Len(t, r.Interface, 2)
+		assert.True(t, next.EOF())
+	})
+
+	t.Run("no end", func(t *testing.T) {
+		s := NewStringScanner("abc")
+
+		r, _ := Parse(p, s)
+
+		var pe *ParseError
+		assert.False(t, r.Matched())
+		assert.True(t, errors.As(r.Err, &pe))
+		assert.Equal(t, 2, pe.Offset())
+		assert.EqualError(t, r.Err, "line 1 col 3: expected one of '.', 'a', 'b' but found 'c'")
+	})
+}
+
+func TestManyRunesTill(t *testing.T) {
+	comment := Sequence(
+		func(results []Result, begin, end Scanner) Result {
+			return results[1]
+		},
+		Token("/*"),
+		ManyRunesTill(Take(1), Token("*/")),
+	)
+
+	t.Run("match", func(t *testing.T) {
+		s := NewStringScanner("/* a * b */c")
+
+		r, next := comment.Parse(s)
+
+		expected := Result{
+			Runes: []rune(" a * b "),
+			Span:  lineSpan(2, 9),
+		}
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, expected, r)
+		assert.Equal(t, 11, next.Offset())
+	})
+
+	t.Run("unterminated", func(t *testing.T) {
+		s := NewStringScanner("/* a")
+
+		r, _ := Parse(comment, s)
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, `line 1 col 5: expected one of "*/", any character but found EOF`)
+	})
+}
+
+func TestSkipUntil(t *testing.T) {
+	p := SkipUntil(Char(';'))
+
+	t.Run("match", func(t *testing.T) {
+		s := NewStringScanner("abc;d")
+
+		r, next := p.Parse(s)
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, Result{Runes: []rune(";"), Span: lineSpan(3, 4)}, r)
+		assert.Equal(t, 4, next.Offset())
+	})
+
+	t.Run("EOF", func(t *testing.T) {
+		s := NewStringScanner("abc")
+
+		r, _ := p.Parse(s)
+
+		assert.False(t, r.Matched())
+		assert.True(t, errors.Is(r.Err, io.EOF))
+	})
+}
+
+func TestManyStalled(t *testing.T) {
+	if debug {
+		t.Skip("stalled repetitions panic in debug builds")
+	}
+
+	empty := Maybe(Char('a'))
+
+	tests := []struct {
+		name   string
+		parser Parser
+		runes  string
+	}{
+		{"Many", Many(nil, empty), ""},
+		{"ManyRunes", ManyRunes(empty), "aa"},
+		{"OnePlus", OnePlus(nil, empty), ""},
+		{"OnePlusRunes", OnePlusRunes(empty), "aa"},
+		{"AtLeastRunes", AtLeastRunes(0, empty), "aa"},
+		{"SepByRunes", SepByRunes(empty, Maybe(Char(','))), "aa"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, next := test.parser.Parse(NewStringScanner("aab"))
+
+			assert.True(t, r.Matched())
+			assert.Equal(t, 2, next.Offset())
+			if test.runes != "" {
+				assert.Equal(t, test.runes, string(r.Runes))
+			}
+		})
+	}
+
+	t.Run("results", func(t *testing.T) {
+		r, _ := Many(nil, empty).Parse(NewStringScanner("b"))
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, []Result(nil), r.Interface)
+	})
+
+	t.Run("ManyTill", func(t *testing.T) {
+		p := ManyTill(nil, Label("maybe a", empty), Char('.'))
+
+		r, next := p.Parse(NewStringScanner("aab"))
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 3: maybe a matched without consuming input")
+		assert.Equal(t, 2, next.Offset())
+	})
+}
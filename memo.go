@@ -0,0 +1,95 @@
+package comb
+
+// Memo memoizes a parser, so that it is run at most once at each position
+// of the input. Later attempts to parse at the same position return the
+// cached result and scanner. Memoizing the rules of a grammar which
+// backtracks heavily makes parsing take linear time (packrat parsing),
+// at the cost of memory proportional to the input.
+//
+// The cache belongs to a single call of Parse or ParseAll, and is cleared
+// when it returns, so a memoized parser can be reused for many inputs.
+// Outside of Parse and ParseAll, Memo has no effect.
+//
+// To memoize every rule of a grammar without modifying it, use the
+// MemoizeReferences option.
+func Memo(parser Parser) Parser {
+	return &memoParser{parser: parser}
+}
+
+type memoParser struct {
+	parser Parser
+}
+
+func (m *memoParser) Parse(s Scanner) (Result, Scanner) {
+	if s.st == nil {
+		return m.parser.Parse(s)
+	}
+	return s.st.memo(m, m.parser, s)
+}
+
+func (m *memoParser) parserName() string {
+	return parserName(m.parser)
+}
+
+// MemoizeReferences memoizes every parser referred to by Reference,
+// as if each were wrapped in Memo.
+func MemoizeReferences() Option {
+	return func(st *state) {
+		st.memoizeReferences = true
+	}
+}
+
+// memoKey identifies a memoized parser at a position.
+type memoKey struct {
+	id     interface{}
+	offset int
+}
+
+type memoEntry struct {
+	r    Result
+	next Scanner
+
+	// failure is the furthest failure discarded while parsing, which must
+	// be recorded again each time the entry is used.
+	failure *ParseError
+
+	// errors are the errors recovered while parsing, which must be
+	// collected again each time the entry is used.
+	errors []error
+}
+
+// memo runs p at s, or returns its cached result. id identifies p.
+func (st *state) memo(id interface{}, p Parser, s Scanner) (Result, Scanner) {
+	// Results found while a left recursive rule is growing may depend on
+	// its seed, so they are not cached.
+	if st.heads[s.i] > 0 {
+		return p.Parse(s)
+	}
+
+	key := memoKey{id: id, offset: s.i}
+
+	if e, ok := st.memos[key]; ok {
+		if e.failure != nil {
+			st.record(e.failure)
+		}
+		st.errors = append(st.errors, e.errors...)
+		return e.r, e.next
+	}
+
+	mark := st.begin()
+	r, next := p.Parse(s)
+	failure := st.furthest
+	st.end(mark, nil)
+
+	if st.memos == nil {
+		st.memos = make(map[memoKey]memoEntry)
+	}
+	st.memos[key] = memoEntry{
+		r:       r,
+		next:    next,
+		failure: failure,
+		errors:  st.recovered(mark),
+	}
+
+	return r, next
+}
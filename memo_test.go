@@ -0,0 +1,130 @@
+package comb
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func countingParser(p Parser, calls *int) Parser {
+	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		*calls++
+		return p.Parse(s)
+	})
+}
+
+func TestMemo(t *testing.T) {
+	t.Run("cached", func(t *testing.T) {
+		calls := 0
+		word := Memo(countingParser(Token("foo"), &calls))
+		p := Or(
+			Sequence(nil, word, Char('!')),
+			Sequence(nil, word, Char('?')),
+		)
+
+		r, next := Parse(p, NewStringScanner("foo?"))
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("per parse", func(t *testing.T) {
+		calls := 0
+		word := Memo(countingParser(Token("foo"), &calls))
+
+		Parse(word, NewStringScanner("foo"))
+		Parse(word, NewStringScanner("foo"))
+
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("without Parse", func(t *testing.T) {
+		calls := 0
+		word := Memo(countingParser(Token("foo"), &calls))
+		p := Or(
+			Sequence(nil, word, Char('!')),
+			Sequence(nil, word, Char('?')),
+		)
+
+		r, _ := p.Parse(NewStringScanner("foo?"))
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		word := Memo(Sequence(nil, Token("foo"), Char('.')))
+		p := Or(
+			Sequence(nil, word, Char('!')),
+			Sequence(nil, word, Char('?')),
+		)
+
+		r, _ := Parse(p, NewStringScanner("foo,"))
+
+		var pe *ParseError
+		assert.False(t, r.Matched())
+		assert.True(t, errors.As(r.Err, &pe))
+		assert.Equal(t, 3, pe.Offset())
+		assert.Equal(t, []string{"'.'"}, pe.Expected)
+	})
+
+	t.Run("MemoizeReferences", func(t *testing.T) {
+		calls := 0
+		var item Parser
+		ref := Reference(&item)
+		item = countingParser(CharRange('a', 'z'), &calls)
+		p := Or(
+			Sequence(nil, ref, ref, Char('!')),
+			Sequence(nil, ref, ref, Char('?')),
+		)
+
+		r, _ := Parse(p, NewStringScanner("ab?"), MemoizeReferences())
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, 2, calls)
+
+		calls = 0
+		r, _ = Parse(p, NewStringScanner("ab?"))
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, 4, calls)
+	})
+}
+
+// backtracking builds a grammar which takes exponential time to fail
+// without memoization.
+func backtracking(memo func(Parser) Parser) Parser {
+	var expr Parser
+	ref := memo(Reference(&expr))
+
+	expr = Or(
+		Sequence(nil, Char('('), ref, Char(')'), Char('+')),
+		Sequence(nil, Char('('), ref, Char(')'), Char('-')),
+		Char('x'),
+	)
+
+	return expr
+}
+
+func BenchmarkMemo(b *testing.B) {
+	input := strings.Repeat("(", 12) + "x" + strings.Repeat(")", 12)
+
+	b.Run("without", func(b *testing.B) {
+		p := backtracking(func(p Parser) Parser { return p })
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			Parse(p, NewStringScanner(input))
+		}
+	})
+
+	b.Run("with", func(b *testing.B) {
+		p := backtracking(Memo)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			Parse(p, NewStringScanner(input))
+		}
+	})
+}
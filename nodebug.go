@@ -0,0 +1,5 @@
+//go:build !combdebug
+
+package comb
+
+const debug = false
@@ -1,17 +1,41 @@
 package comb
 
 // Or checks parsers in order, returning the first match.
+//
+// If no parser matches, the error which reached furthest into the input
+// is returned, with the expected sets of every error at that position
+// merged together. A committed failure is returned immediately.
 func Or(parsers ...Parser) Parser {
 	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		mark := s.st.begin()
+		pin := s.st.pin(s)
+		var failure *ParseError
+
 		for _, p := range parsers {
+			if r, ok := s.st.step(s); !ok {
+				s.st.unpin(pin)
+				return r, s
+			}
+
 			r, next := p.Parse(s)
 
 			if r.Matched() {
+				s.st.end(mark, failure)
+				s.st.unpin(pin)
+				return r, next
+			}
+
+			if r.Committed {
+				s.st.end(mark, failure)
+				s.st.unpin(pin)
 				return r, next
 			}
+
+			failure = s.st.collect(failure, r.Err)
 		}
 
-		return Failedf("no parser matched"), s
+		s.st.unpin(pin)
+		return orFailed(s, s.st.end(mark, failure)), s
 	})
 }
 
@@ -21,30 +45,63 @@ func Or(parsers ...Parser) Parser {
 // so keep that in mind.
 func OrLongest(parsers ...Parser) Parser {
 	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		mark := s.st.begin()
+		pin := s.st.pin(s)
+		var failure *ParseError
+
 		matched := false
 		var maxResult Result
 		var maxNext Scanner
+		var maxErrors []error
 		first := true
 
 		for _, p := range parsers {
+			if r, ok := s.st.step(s); !ok {
+				s.st.unpin(pin)
+				return r, s
+			}
+
+			// Only the errors recovered by the chosen parser are kept.
 			r, next := p.Parse(s)
+			errs := s.st.takeRecovered(mark)
+
+			if r.Committed {
+				s.st.keepRecovered(errs)
+				s.st.end(mark, failure)
+				s.st.unpin(pin)
+				return r, next
+			}
 
 			if !r.Matched() {
+				failure = s.st.collect(failure, r.Err)
 				continue
 			}
 
 			matched = true
-			if first || len(s.Between(next)) > len(s.Between(maxNext)) {
+			if first || next.i > maxNext.i {
 				first = false
 				maxResult = r
 				maxNext = next
+				maxErrors = errs
 			}
 		}
 
+		failure = s.st.end(mark, failure)
+
 		if !matched {
-			return Failedf("no parser matched"), s
+			s.st.unpin(pin)
+			return orFailed(s, failure), s
 		}
 
+		s.st.keepRecovered(maxErrors)
+		s.st.unpin(pin)
 		return maxResult, maxNext
 	})
 }
+
+func orFailed(s Scanner, failure *ParseError) Result {
+	if failure == nil {
+		return FailedAtf(s, nil, "no parser matched")
+	}
+	return Failed(failure)
+}
@@ -21,6 +21,7 @@ func TestOr(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune("a"),
+			Span:  lineSpan(0, 1),
 		}
 
 		assert.True(t, r.Matched())
@@ -35,6 +36,7 @@ func TestOr(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune("b"),
+			Span:  lineSpan(0, 1),
 		}
 
 		assert.True(t, r.Matched())
@@ -49,6 +51,7 @@ func TestOr(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune("foobar"),
+			Span:  lineSpan(0, 6),
 		}
 
 		assert.True(t, r.Matched())
@@ -62,7 +65,21 @@ func TestOr(t *testing.T) {
 		r, _ := p.Parse(s)
 
 		assert.False(t, r.Matched())
-		assert.EqualError(t, r.Err, "no parser matched")
+		assert.EqualError(t, r.Err, `line 1 col 1: expected one of 'a', 'b', "foobar", "fizzbuzz", "hello", 'f' but found '1'`)
+	})
+
+	t.Run("furthest", func(t *testing.T) {
+		p := Or(
+			SequenceRunes(Char('a'), Char('b')),
+			SequenceRunes(Char('a'), Char('c'), Char('d')),
+			SequenceRunes(Char('a'), Char('c'), Char('e')),
+		)
+		s := NewStringScanner("acx")
+
+		r, _ := p.Parse(s)
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 3: expected one of 'd', 'e' but found 'x'")
 	})
 }
 
@@ -98,6 +115,7 @@ func TestOrLongest(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune("foobar"),
+			Span:  lineSpan(0, 6),
 		}
 
 		assert.True(t, r.Matched())
@@ -111,7 +129,7 @@ func TestOrLongest(t *testing.T) {
 		r, _ := p.Parse(s)
 
 		assert.False(t, r.Matched())
-		assert.EqualError(t, r.Err, "no parser matched")
+		assert.EqualError(t, r.Err, `line 1 col 1: expected one of 'a', 'f', "foobar", "fizzbuzz", "hello" but found '1'`)
 	})
 }
 
@@ -0,0 +1,366 @@
+package comb
+
+import "context"
+
+// Parse runs a parser over a scanner, like p.Parse(s), but tracks failures
+// across the entire parse. When the parse fails, the error returned is the
+// failure that reached furthest into the input, with the expected sets of
+// every failure at that position merged together, even those that were
+// discarded by parsers like Maybe and Many.
+//
+// Options may be given to configure the parse.
+func Parse(p Parser, s Scanner, opts ...Option) (Result, Scanner) {
+	return parse(p, s, newState(opts))
+}
+
+// ParseAll is like Parse, but enables error recovery. When a parser wrapped
+// in Recover fails, its error is collected and parsing continues. ParseAll
+// returns the (possibly partial) result, along with every error collected
+// in the order they occurred. If the parse as a whole failed, its error is
+// last.
+func ParseAll(p Parser, s Scanner, opts ...Option) (Result, Scanner, []error) {
+	st := newState(opts)
+	st.recover = true
+
+	r, next := parse(p, s, st)
+	errs := st.errors
+	if !r.Matched() {
+		errs = append(errs, st.pending...)
+		errs = append(errs, r.Err)
+	}
+
+	return r, next, errs
+}
+
+func parse(p Parser, s Scanner, st *state) (Result, Scanner) {
+	orig := s.input
+	in := *orig
+	in.st = st
+	s.input = &in
+
+	if s.stream != nil {
+		limit, limitErr := s.stream.setLimit(s.i, st.maxInputSize)
+		defer func() { s.stream.limit, s.stream.limitErr = limit, limitErr }()
+	}
+
+	var r Result
+	next := s
+
+	st.checkInputSize(s)
+	if st.ctx != nil && st.stopped == nil {
+		st.checkContext(s)
+	}
+
+	if st.stopped == nil {
+		r, next = p.Parse(s)
+		if !r.Matched() {
+			r = s.st.fail(scope{}, r)
+		}
+	}
+
+	// Once the parse has stopped, its results cannot be trusted, even if
+	// a parser such as Recover carried on.
+	if st.stopped != nil {
+		r = Result{Err: st.stopped, Committed: true}
+	}
+
+	detach(r.Err, &in, orig)
+	for _, err := range st.errors {
+		detach(err, &in, orig)
+	}
+	for _, err := range st.pending {
+		detach(err, &in, orig)
+	}
+
+	if next.input == &in {
+		next.input = orig
+	}
+	return r, next
+}
+
+// detach points the scanners held by an error of a finished parse back to
+// the original input, as for the scanner it returns, so that keeping the
+// error does not keep the state of the parse and its caches alive.
+func detach(err error, in, orig *input) {
+	for pe, ok := err.(*ParseError); ok && pe != nil; pe, ok = pe.Cause.(*ParseError) {
+		if pe.Scanner.input == in {
+			pe.Scanner.input = orig
+		}
+	}
+}
+
+// Option configures a parse started by Parse or ParseAll.
+type Option func(*state)
+
+// state is shared by every Scanner derived from the Scanner given to Parse.
+// All of its methods may be called on a nil *state, in which case nothing
+// is tracked.
+type state struct {
+	furthest *ParseError
+
+	recover bool
+	errors  []error
+
+	// pending holds the errors recovered within a parser which failed.
+	// They are reported if the failure ends the parse, but dropped if
+	// the failure is backtracked from.
+	pending []error
+
+	memoizeReferences bool
+	memos             map[memoKey]memoEntry
+
+	// heads counts the left recursive rules being grown at each offset.
+	heads map[int]int
+
+	// pins holds the offsets of a reader scanner which parsers may still
+	// return to, outermost first. Commit points only release the input
+	// before the first of them.
+	pins []int
+
+	maxDepth     int
+	maxSteps     int
+	maxInputSize int
+	depth        int
+	steps        int
+
+	ctx context.Context
+
+	trace      func(TraceEvent)
+	traceDepth int
+
+	profiler      *Profiler
+	profileStack  []profileFrame
+	profileActive map[string]int
+	profileSeen   map[profileKey]struct{}
+
+	// stopped is the failure of a parse which has been stopped.
+	stopped *ParseError
+}
+
+func newState(opts []Option) *state {
+	st := &state{}
+	for _, opt := range opts {
+		opt(st)
+	}
+	return st
+}
+
+// record notes an error which is being discarded, so that it can be
+// reported later if nothing gets further into the input. The parser which
+// failed is backtracked from, so the errors recovered within it are dropped.
+func (st *state) record(err error) {
+	if st == nil {
+		return
+	}
+	st.furthest = mergeFailure(st.furthest, err)
+	st.pending = nil
+}
+
+// scope marks the beginning of a failure scope.
+type scope struct {
+	furthest *ParseError
+	errors   int
+}
+
+// begin starts a new failure scope, returning a mark to be passed to end.
+// Failures which happened before the scope began are not reported by it.
+func (st *state) begin() scope {
+	if st == nil {
+		return scope{}
+	}
+
+	mark := scope{furthest: st.furthest, errors: len(st.errors)}
+	st.furthest = nil
+	return mark
+}
+
+// end ends a failure scope, merging err into it, and returns the furthest
+// failure in the scope. The failures are kept to be reported by the
+// enclosing scope.
+func (st *state) end(mark scope, err error) *ParseError {
+	if st == nil {
+		return mergeFailure(nil, err)
+	}
+
+	pe := mergeFailure(st.furthest, err)
+	st.furthest = mergeFailure(mark.furthest, pe)
+	return pe
+}
+
+// fail ends a failure scope for a failed result, replacing its error
+// with the furthest failure in the scope if there is one.
+func (st *state) fail(mark scope, r Result) Result {
+	if st == nil {
+		return r
+	}
+
+	st.suspend(mark)
+	if pe := st.end(mark, r.Err); pe != nil {
+		r.Err = pe
+	}
+	return r
+}
+
+// reject ends a failure scope for a failure which is final, such as one
+// reported by a user function rather than a failure to match. The failures
+// in the scope are discarded so that they do not replace its error, and the
+// result is committed so that nothing is tried in its place.
+func (st *state) reject(mark scope, r Result) Result {
+	if st != nil {
+		st.suspend(mark)
+		st.furthest = mark.furthest
+	}
+	r.Committed = true
+	return r
+}
+
+// discard ends a failure scope, discarding the failures in it, along with
+// any errors recovered within it.
+func (st *state) discard(mark scope) {
+	if st != nil {
+		st.furthest = mark.furthest
+		st.errors = st.errors[:mark.errors]
+		st.pending = nil
+	}
+}
+
+// suspend moves the errors recovered since a scope began to pending, as
+// the scope's parser failed.
+func (st *state) suspend(mark scope) {
+	if errs := st.recovered(mark); errs != nil {
+		st.pending = append(errs, st.pending...)
+		st.errors = st.errors[:mark.errors]
+	}
+}
+
+// recovered returns a copy of the errors recovered since a scope began.
+func (st *state) recovered(mark scope) []error {
+	if len(st.errors) == mark.errors {
+		return nil
+	}
+	return append([]error(nil), st.errors[mark.errors:]...)
+}
+
+// takeRecovered removes the errors recovered since a scope began, so that
+// they are only kept if given to keepRecovered.
+func (st *state) takeRecovered(mark scope) []error {
+	if st == nil {
+		return nil
+	}
+
+	errs := st.recovered(mark)
+	st.errors = st.errors[:mark.errors]
+	return errs
+}
+
+// keepRecovered collects errors returned by takeRecovered.
+func (st *state) keepRecovered(errs []error) {
+	if st != nil {
+		st.errors = append(st.errors, errs...)
+	}
+}
+
+// label ends a failure scope for a labelled parser. If the furthest failure
+// in the scope is at s, it is replaced by one which expects the label.
+func (st *state) label(mark scope, s Scanner, expected []string, err error) *ParseError {
+	var pe *ParseError
+	if st != nil {
+		pe = st.furthest
+		if err != nil {
+			st.suspend(mark)
+		}
+	}
+	pe = mergeFailure(pe, err)
+
+	if pe == nil && err != nil || pe != nil && pe.Offset() <= s.i {
+		labelled := &ParseError{
+			Scanner:  s,
+			Expected: expected,
+		}
+		if pe != nil {
+			labelled.Cause = pe.Cause
+		}
+		pe = labelled
+	}
+
+	if st != nil {
+		st.furthest = mergeFailure(mark.furthest, pe)
+	}
+
+	return pe
+}
+
+// collect accumulates the error of a failed alternative. If there is state,
+// the error is recorded there, otherwise it is merged into failure.
+func (st *state) collect(failure *ParseError, err error) *ParseError {
+	if st != nil {
+		st.record(err)
+		return nil
+	}
+	return mergeFailure(failure, err)
+}
+
+// mergeFailure returns the error which reached furthest into the input. If
+// both errors are at the same position, their expected sets are combined.
+// Errors other than *ParseError carry no position and are ignored.
+func mergeFailure(a *ParseError, err error) *ParseError {
+	b, ok := err.(*ParseError)
+	if !ok || b == nil || a == b {
+		return a
+	}
+
+	if a == nil {
+		return b
+	}
+
+	switch {
+	case a.Offset() > b.Offset():
+		return a
+	case a.Offset() < b.Offset():
+		return b
+	}
+
+	if len(b.Expected) == 0 {
+		return a
+	}
+
+	if len(a.Expected) == 0 {
+		return b
+	}
+
+	var added []string
+
+	for _, e := range b.Expected {
+		if !containsString(a.Expected, e) && !containsString(added, e) {
+			added = append(added, e)
+		}
+	}
+
+	if len(added) == 0 {
+		return a
+	}
+
+	expected := make([]string, 0, len(a.Expected)+len(added))
+	expected = append(expected, a.Expected...)
+	expected = append(expected, added...)
+
+	merged := &ParseError{
+		Scanner:  a.Scanner,
+		Expected: expected,
+	}
+
+	if a.Cause == b.Cause {
+		merged.Cause = a.Cause
+	}
+
+	return merged
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
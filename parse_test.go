@@ -0,0 +1,74 @@
+package comb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	item := CharRange('a', 'z')
+	p := Sequence(
+		nil,
+		Char('{'),
+		item,
+		Many(nil, Sequence(nil, Char(','), item)),
+		Char('}'),
+	)
+
+	t.Run("match", func(t *testing.T) {
+		s := NewStringScanner("{a,b,c}")
+
+		r, next := Parse(p, s)
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+	})
+
+	t.Run("furthest", func(t *testing.T) {
+		s := NewStringScanner("{a,b]")
+
+		r, _ := Parse(p, s)
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 5: expected one of ',', '}' but found ']'")
+	})
+
+	t.Run("discarded", func(t *testing.T) {
+		s := NewStringScanner("{a,1}")
+
+		r, _ := Parse(p, s)
+
+		pe, ok := r.Err.(*ParseError)
+		assert.True(t, ok)
+		assert.Equal(t, 3, pe.Offset())
+		assert.Equal(t, []string{"'a'-'z'"}, pe.Expected)
+	})
+
+	t.Run("detached", func(t *testing.T) {
+		s := NewStringScanner("{a,b]")
+
+		r, next := Parse(p, s)
+
+		assert.Nil(t, next.st)
+		assert.Nil(t, r.Err.(*ParseError).Scanner.st)
+
+		rec := Sequence(nil, Recover(p, Char(']')), Char('!'))
+		_, _, errs := ParseAll(rec, NewStringScanner("{a,b]"))
+
+		if assert.Len(t, errs, 2) {
+			for _, err := range errs {
+				assert.Nil(t, err.(*ParseError).Scanner.st)
+			}
+		}
+	})
+
+	t.Run("without Parse", func(t *testing.T) {
+		s := NewStringScanner("{a,b]")
+
+		r, _ := p.Parse(s)
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 5: expected '}' but found ']'")
+	})
+}
@@ -1,6 +1,7 @@
 package comb
 
 import (
+	"fmt"
 	"io"
 )
 
@@ -25,20 +26,127 @@ func ParserFunc(fn func(Scanner) (Result, Scanner)) Parser {
 }
 
 // Reference takes a pointer to a Parser, and only dereferences it
-// when Parse is called.
+// when Parse is called. When parsing with the MemoizeReferences option,
+// the referenced parser is memoized as if wrapped in Memo.
 func Reference(p *Parser) Parser {
-	return ParserFunc(func(s Scanner) (Result, Scanner) {
+	return referenceParser{p: p}
+}
+
+type referenceParser struct {
+	p *Parser
+}
+
+func (ref referenceParser) Parse(s Scanner) (Result, Scanner) {
+	p := ref.p
+	st := s.st
+	if st == nil {
 		return (*p).Parse(s)
-	})
+	}
+
+	if r, ok := st.enter(s); !ok {
+		return r, s
+	}
+
+	var r Result
+	var next Scanner
+	if st.memoizeReferences {
+		r, next = st.memo(p, *p, s)
+	} else {
+		r, next = (*p).Parse(s)
+	}
+
+	st.leave()
+	return r, next
+}
+
+func (ref referenceParser) parserName() string {
+	return parserName(*ref.p)
 }
 
 // Tag sets the tag of a parser's result.
 func Tag(tag string, parser Parser) Parser {
-	return ParserFunc(func(s Scanner) (Result, Scanner) {
-		r, next := parser.Parse(s)
-		r.Tag = tag
-		return r, next
-	})
+	return tagParser{tag: tag, parser: parser}
+}
+
+type tagParser struct {
+	tag    string
+	parser Parser
+}
+
+func (t tagParser) Parse(s Scanner) (Result, Scanner) {
+	s.st.profileEnter(t.tag)
+	r, next := t.parser.Parse(s)
+	s.st.profileExit(t.tag, s, next, r)
+
+	r.Tag = t.tag
+	return r, next
+}
+
+func (t tagParser) parserName() string {
+	return t.tag
+}
+
+// Label names a parser in error messages. If the parser fails without
+// consuming any input, its error is replaced with one expecting name,
+// so that "expected expression" is reported rather than a list of
+// characters. Failures after input has been consumed are left as-is.
+func Label(name string, parser Parser) Parser {
+	return labelParser{
+		name:     name,
+		parser:   parser,
+		expected: []string{name},
+	}
+}
+
+type labelParser struct {
+	name     string
+	parser   Parser
+	expected []string
+}
+
+func (l labelParser) Parse(s Scanner) (Result, Scanner) {
+	pin := s.st.traceEnter(l.name, s)
+	s.st.profileEnter(l.name)
+
+	mark := s.st.begin()
+	r, next := l.parser.Parse(s)
+
+	pe := s.st.label(mark, s, l.expected, r.Err)
+	if !r.Matched() {
+		r.Err = pe
+	}
+
+	s.st.profileExit(l.name, s, next, r)
+	s.st.traceExit(l.name, pin, s, next, r)
+	return r, next
+}
+
+func (l labelParser) parserName() string {
+	return l.name
+}
+
+// namedParser is implemented by parsers which can be named in messages,
+// such as those made by Label and Tag, and the parsers which refer to them.
+type namedParser interface {
+	parserName() string
+}
+
+// parserName returns the label or tag of a parser, looking through
+// references, or "" if it has neither.
+func parserName(p Parser) string {
+	if n, ok := p.(namedParser); ok {
+		return n.parserName()
+	}
+	return ""
+}
+
+// describe names a parser in debugging messages, using its label or tag
+// if it has one, or else its type.
+func describe(p Parser) string {
+	if name := parserName(p); name != "" {
+		return name
+	}
+	return fmt.Sprintf("%T", p)
 }
 
 // Ignore sets the result of a Parser to be Ignored.
@@ -50,15 +158,19 @@ func Ignore(parser Parser) Parser {
 	})
 }
 
+var eofExpected = []string{"EOF"}
+
 // EOF matches only at EOF.
 func EOF() Parser {
 	return ParserFunc(func(s Scanner) (Result, Scanner) {
-		r, next, err := s.Next()
+		_, next, err := s.Next()
 		if err != io.EOF {
-			return Failedf("expected EOF, got '%c'", r), next
+			return FailedAt(s, eofExpected...), next
 		}
 
-		return Result{}, next
+		return Result{
+			Span: s.Span(next),
+		}, next
 	})
 }
 
@@ -66,10 +178,15 @@ func EOF() Parser {
 // otherwise, it returns an empty result and the original scanner.
 func Maybe(parser Parser) Parser {
 	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		pin := s.st.pin(s)
 		r, next := parser.Parse(s)
-		if r.Matched() {
+		s.st.unpin(pin)
+		if r.Matched() || r.Committed {
 			return r, next
 		}
-		return Result{}, s
+		s.st.record(r.Err)
+		return Result{
+			Span: s.Span(s),
+		}, s
 	})
 }
@@ -15,6 +15,7 @@ func TestTag(t *testing.T) {
 	expected := Result{
 		Runes: []rune("a"),
 		Tag:   "foobar",
+		Span:  lineSpan(0, 1),
 	}
 
 	assert.True(t, r.Matched())
@@ -31,6 +32,7 @@ func TestIgnore(t *testing.T) {
 	expected := Result{
 		Runes:  []rune("a"),
 		Ignore: true,
+		Span:   lineSpan(0, 1),
 	}
 
 	assert.True(t, r.Matched())
@@ -47,6 +49,7 @@ func TestReference(t *testing.T) {
 
 	expected := Result{
 		Runes: []rune("a"),
+		Span:  lineSpan(0, 1),
 	}
 
 	assert.True(t, r.Matched())
@@ -61,7 +64,9 @@ func TestEOF(t *testing.T) {
 
 		r, next := p.Parse(s)
 
-		expected := Result{}
+		expected := Result{
+			Span: lineSpan(0, 0),
+		}
 
 		assert.True(t, r.Matched())
 		assert.Equal(t, expected, r)
@@ -75,7 +80,7 @@ func TestEOF(t *testing.T) {
 		r, next := p.Parse(s)
 
 		assert.False(t, r.Matched())
-		assert.EqualError(t, r.Err, "expected EOF, got 'a'")
+		assert.EqualError(t, r.Err, "line 1 col 1: expected EOF but found 'a'")
 		assert.False(t, next.EOF())
 	})
 }
@@ -93,6 +98,7 @@ func TestMaybe(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune("ab"),
+			Span:  lineSpan(0, 2),
 		}
 
 		assert.True(t, r.Matched())
@@ -107,10 +113,109 @@ func TestMaybe(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune("b"),
+			Span:  lineSpan(0, 1),
+		}
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, expected, r)
+		assert.True(t, next.EOF())
+	})
+}
+
+func TestDescribe(t *testing.T) {
+	label := Label("maybe a", Maybe(Char('a')))
+	labelOf := LabelOf("typed a", Typed(Maybe(Char('a')), func(r Result) string { return r.AsString() }))
+	var ref Parser = Tag("tagged a", Maybe(Char('a')))
+
+	tests := []struct {
+		parser Parser
+		name   string
+	}{
+		{label, "maybe a"},
+		{Tag("tagged", label), "tagged"},
+		{Reference(&ref), "tagged a"},
+		{Memo(label), "maybe a"},
+		{LeftRec(&ref), "tagged a"},
+		{labelOf, "typed a"},
+		{ReferenceOf(&labelOf), "typed a"},
+		{LeftRecOf(&labelOf), "typed a"},
+		{Maybe(Char('a')), "comb.parserFunc"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.name, describe(test.parser))
+		})
+	}
+
+	t.Run("unset reference", func(t *testing.T) {
+		var unset Parser
+		assert.Equal(t, "comb.referenceParser", describe(Reference(&unset)))
+	})
+}
+
+func TestLabel(t *testing.T) {
+	digits := Label("number", OnePlusRunes(CharRange('0', '9')))
+
+	t.Run("match", func(t *testing.T) {
+		s := NewStringScanner("123")
+
+		r, next := digits.Parse(s)
+
+		expected := Result{
+			Runes: []rune("123"),
+			Span:  lineSpan(0, 3),
 		}
 
 		assert.True(t, r.Matched())
 		assert.Equal(t, expected, r)
 		assert.True(t, next.EOF())
 	})
+
+	t.Run("no match", func(t *testing.T) {
+		s := NewStringScanner("x")
+
+		r, _ := digits.Parse(s)
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 1: expected number but found 'x'")
+	})
+
+	t.Run("consumed", func(t *testing.T) {
+		p := Label("pair", Sequence(nil, Char('('), Char(')')))
+		s := NewStringScanner("(x")
+
+		r, _ := p.Parse(s)
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 2: expected ')' but found 'x'")
+	})
+
+	t.Run("Or", func(t *testing.T) {
+		p := Or(
+			digits,
+			Label("identifier", OnePlusRunes(CharRange('a', 'z'))),
+		)
+		s := NewStringScanner("+")
+
+		r, _ := p.Parse(s)
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 1: expected one of number, identifier but found '+'")
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		p := Sequence(
+			nil,
+			digits,
+			Label("operator", Char('+', '-')),
+			digits,
+		)
+		s := NewStringScanner("12*3")
+
+		r, _ := Parse(p, s)
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 3: expected one of '0'-'9', operator but found '*'")
+	})
 }
@@ -0,0 +1,142 @@
+package peg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jakebailey/comb"
+)
+
+// rule is a named parsing expression.
+type rule struct {
+	name string
+	pos  comb.Pos
+	expr expr
+}
+
+// expr is a parsing expression. Its String method formats it as it
+// would be written in a grammar.
+type expr interface {
+	String() string
+}
+
+type choiceExpr struct {
+	alts []expr
+}
+
+func (e *choiceExpr) String() string {
+	alts := make([]string, len(e.alts))
+	for i, alt := range e.alts {
+		alts[i] = alt.String()
+	}
+	return "(" + strings.Join(alts, " / ") + ")"
+}
+
+type seqExpr struct {
+	items []expr
+}
+
+func (e *seqExpr) String() string {
+	items := make([]string, len(e.items))
+	for i, item := range e.items {
+		items[i] = item.String()
+	}
+	return "(" + strings.Join(items, " ") + ")"
+}
+
+// repeatExpr is a repetition, where op is one of '*', '+', or '?'.
+type repeatExpr struct {
+	op   byte
+	expr expr
+}
+
+func (e *repeatExpr) String() string {
+	return e.expr.String() + string(e.op)
+}
+
+// predicateExpr is a lookahead, where op is one of '&' or '!'.
+type predicateExpr struct {
+	op   byte
+	expr expr
+}
+
+func (e *predicateExpr) String() string {
+	return string(e.op) + e.expr.String()
+}
+
+type literalExpr struct {
+	text string
+}
+
+func (e *literalExpr) String() string {
+	return strconv.Quote(e.text)
+}
+
+type charRange struct {
+	lo, hi rune
+}
+
+type classExpr struct {
+	negated bool
+	chars   []rune
+	ranges  []charRange
+}
+
+func (e *classExpr) String() string {
+	var b strings.Builder
+	b.WriteByte('[')
+	if e.negated {
+		b.WriteByte('^')
+	}
+	for _, c := range e.chars {
+		b.WriteString(classChar(c))
+	}
+	for _, r := range e.ranges {
+		b.WriteString(classChar(r.lo) + "-" + classChar(r.hi))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+func classChar(c rune) string {
+	switch c {
+	case ']', '-', '^', '\\':
+		return `\` + string(c)
+	}
+	q := strconv.QuoteRune(c)
+	return q[1 : len(q)-1]
+}
+
+type anyExpr struct{}
+
+func (anyExpr) String() string {
+	return "."
+}
+
+type regexpExpr struct {
+	pattern string
+}
+
+func (e *regexpExpr) String() string {
+	return "`" + e.pattern + "`"
+}
+
+type refExpr struct {
+	name string
+	pos  comb.Pos
+}
+
+func (e *refExpr) String() string {
+	return e.name
+}
+
+// Error is an error in the text of a grammar.
+type Error struct {
+	Pos comb.Pos
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("peg: %s: %s", e.Pos, e.Msg)
+}
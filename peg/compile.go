@@ -0,0 +1,373 @@
+package peg
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jakebailey/comb"
+)
+
+// Actions binds rule names to the combiners which build their results.
+type Actions map[string]comb.ResultCombiner
+
+// Grammar is a compiled grammar.
+type Grammar struct {
+	names   []string
+	rules   map[string]*comb.Parser
+	entries map[string]comb.Parser
+}
+
+// Compile compiles the text of a grammar into parsers, binding actions to
+// the rules they are named after. Errors in the grammar are returned as
+// an *Error.
+func Compile(src string, actions Actions) (*Grammar, error) {
+	rules, err := parseGrammar(src)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &compiler{
+		rules: make(map[string]*rule, len(rules)),
+	}
+
+	for _, r := range rules {
+		if prev, ok := c.rules[r.name]; ok {
+			return nil, &Error{
+				Pos: r.pos,
+				Msg: fmt.Sprintf("rule %s already defined at %s", r.name, prev.pos),
+			}
+		}
+		c.rules[r.name] = r
+	}
+
+	var names []string
+	for name := range actions {
+		if _, ok := c.rules[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		return nil, fmt.Errorf("peg: actions for undefined rules: %s", strings.Join(names, ", "))
+	}
+
+	for _, r := range rules {
+		if err := c.check(r.expr); err != nil {
+			return nil, err
+		}
+	}
+
+	c.findLeftRecursion(rules)
+
+	g := &Grammar{
+		rules:   make(map[string]*comb.Parser, len(rules)),
+		entries: make(map[string]comb.Parser, len(rules)),
+	}
+	c.g = g
+
+	for _, r := range rules {
+		g.names = append(g.names, r.name)
+		g.rules[r.name] = new(comb.Parser)
+	}
+
+	for _, r := range rules {
+		p := g.rules[r.name]
+		if c.leftRec[r.name] {
+			g.entries[r.name] = comb.LeftRec(p)
+		} else {
+			g.entries[r.name] = comb.Reference(p)
+		}
+	}
+
+	for _, r := range rules {
+		*g.rules[r.name] = comb.Label(r.name, c.compileRule(r, actions[r.name]))
+	}
+
+	return g, nil
+}
+
+// MustCompile is like Compile, but panics if the grammar has an error.
+func MustCompile(src string, actions Actions) *Grammar {
+	g, err := Compile(src, actions)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// Rule returns the parser for the named rule, or nil if there is no such
+// rule.
+func (g *Grammar) Rule(name string) comb.Parser {
+	return g.entries[name]
+}
+
+// Start returns the parser for the start rule, the first in the grammar.
+func (g *Grammar) Start() comb.Parser {
+	return g.entries[g.names[0]]
+}
+
+// Rules returns the names of the rules, in the order they were defined.
+func (g *Grammar) Rules() []string {
+	return append([]string(nil), g.names...)
+}
+
+type compiler struct {
+	rules   map[string]*rule
+	leftRec map[string]bool
+	g       *Grammar
+}
+
+// check reports references to undefined rules.
+func (c *compiler) check(e expr) error {
+	switch e := e.(type) {
+	case *choiceExpr:
+		for _, alt := range e.alts {
+			if err := c.check(alt); err != nil {
+				return err
+			}
+		}
+	case *seqExpr:
+		for _, item := range e.items {
+			if err := c.check(item); err != nil {
+				return err
+			}
+		}
+	case *repeatExpr:
+		return c.check(e.expr)
+	case *predicateExpr:
+		return c.check(e.expr)
+	case *refExpr:
+		if _, ok := c.rules[e.name]; !ok {
+			return &Error{Pos: e.pos, Msg: "undefined rule " + e.name}
+		}
+	}
+	return nil
+}
+
+// findLeftRecursion finds the rules which can call themselves without
+// consuming any input. References to these rules go through comb.LeftRec.
+func (c *compiler) findLeftRecursion(rules []*rule) {
+	nullable := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for _, r := range rules {
+			if !nullable[r.name] && c.nullable(r.expr, nullable) {
+				nullable[r.name] = true
+				changed = true
+			}
+		}
+	}
+
+	calls := make(map[string][]string, len(rules))
+	for _, r := range rules {
+		calls[r.name] = c.leftCalls(r.expr, nullable, nil)
+	}
+
+	c.leftRec = make(map[string]bool)
+	for _, r := range rules {
+		seen := make(map[string]bool)
+		var reaches func(name string) bool
+		reaches = func(name string) bool {
+			for _, callee := range calls[name] {
+				if callee == r.name {
+					return true
+				}
+				if !seen[callee] {
+					seen[callee] = true
+					if reaches(callee) {
+						return true
+					}
+				}
+			}
+			return false
+		}
+		c.leftRec[r.name] = reaches(r.name)
+	}
+}
+
+// nullable returns true if e can match without consuming any input.
+func (c *compiler) nullable(e expr, rules map[string]bool) bool {
+	switch e := e.(type) {
+	case *choiceExpr:
+		for _, alt := range e.alts {
+			if c.nullable(alt, rules) {
+				return true
+			}
+		}
+		return false
+	case *seqExpr:
+		for _, item := range e.items {
+			if !c.nullable(item, rules) {
+				return false
+			}
+		}
+		return true
+	case *repeatExpr:
+		return e.op != '+' || c.nullable(e.expr, rules)
+	case *predicateExpr:
+		return true
+	case *literalExpr:
+		return e.text == ""
+	case *regexpExpr:
+		return regexp.MustCompile(e.pattern).MatchString("")
+	case *refExpr:
+		return rules[e.name]
+	}
+	return false
+}
+
+// leftCalls appends the rules which e can call before consuming any input.
+func (c *compiler) leftCalls(e expr, nullable map[string]bool, calls []string) []string {
+	switch e := e.(type) {
+	case *choiceExpr:
+		for _, alt := range e.alts {
+			calls = c.leftCalls(alt, nullable, calls)
+		}
+	case *seqExpr:
+		for _, item := range e.items {
+			calls = c.leftCalls(item, nullable, calls)
+			if !c.nullable(item, nullable) {
+				break
+			}
+		}
+	case *repeatExpr:
+		calls = c.leftCalls(e.expr, nullable, calls)
+	case *predicateExpr:
+		calls = c.leftCalls(e.expr, nullable, calls)
+	case *refExpr:
+		calls = append(calls, e.name)
+	}
+	return calls
+}
+
+// compileRule compiles the expression of a rule, using action as the
+// combiner of each of its alternatives.
+func (c *compiler) compileRule(r *rule, action comb.ResultCombiner) comb.Parser {
+	if action == nil {
+		return c.compile(r.expr)
+	}
+
+	choice, ok := r.expr.(*choiceExpr)
+	if !ok {
+		return c.compileAlt(r.expr, action)
+	}
+
+	alts := make([]comb.Parser, len(choice.alts))
+	for i, alt := range choice.alts {
+		alts[i] = c.compileAlt(alt, action)
+	}
+	return comb.Or(alts...)
+}
+
+// compileAlt compiles an alternative of a rule with an action. The action
+// is given a result for each item of the alternative's sequence.
+func (c *compiler) compileAlt(e expr, action comb.ResultCombiner) comb.Parser {
+	if seq, ok := e.(*seqExpr); ok {
+		return comb.Sequence(action, c.compileAll(seq.items)...)
+	}
+	return comb.Sequence(action, c.compile(e))
+}
+
+func (c *compiler) compileAll(exprs []expr) []comb.Parser {
+	parsers := make([]comb.Parser, len(exprs))
+	for i, e := range exprs {
+		parsers[i] = c.compile(e)
+	}
+	return parsers
+}
+
+func (c *compiler) compile(e expr) comb.Parser {
+	switch e := e.(type) {
+	case *choiceExpr:
+		return comb.Or(c.compileAll(e.alts)...)
+	case *seqExpr:
+		return comb.Sequence(nil, c.compileAll(e.items)...)
+	case *repeatExpr:
+		p := c.compile(e.expr)
+		switch e.op {
+		case '*':
+			return comb.Many(nil, p)
+		case '+':
+			return comb.OnePlus(nil, p)
+		default:
+			return comb.Maybe(p)
+		}
+	case *predicateExpr:
+		p := c.compile(e.expr)
+		if e.op == '&' {
+			return comb.Peek(p)
+		}
+		return comb.Not(p)
+	case *literalExpr:
+		if e.text == "" {
+			return empty
+		}
+		return comb.Token(e.text)
+	case *classExpr:
+		return compileClass(e)
+	case anyExpr:
+		return comb.AnyChar()
+	case *regexpExpr:
+		return comb.Regexp(e.pattern)
+	case *refExpr:
+		return c.g.entries[e.name]
+	}
+	panic(fmt.Sprintf("peg: unknown expression %T", e))
+}
+
+// empty matches nothing.
+var empty = comb.ParserFunc(func(s comb.Scanner) (comb.Result, comb.Scanner) {
+	return comb.Result{Span: s.Span(s)}, s
+})
+
+func compileClass(e *classExpr) comb.Parser {
+	if e.negated {
+		if len(e.ranges) == 0 {
+			return comb.NotChar(e.chars...)
+		}
+		return negatedClass(e)
+	}
+
+	var parsers []comb.Parser
+	if len(e.chars) > 0 {
+		parsers = append(parsers, comb.Char(e.chars...))
+	}
+	for _, r := range e.ranges {
+		parsers = append(parsers, comb.CharRange(r.lo, r.hi))
+	}
+
+	if len(parsers) == 1 {
+		return parsers[0]
+	}
+	return comb.Or(parsers...)
+}
+
+// negatedClass accepts a character not in a class with ranges.
+func negatedClass(e *classExpr) comb.Parser {
+	expected := "any character except " + e.String()[2:len(e.String())-1]
+
+	in := func(c rune) bool {
+		for _, r := range e.ranges {
+			if c >= r.lo && c <= r.hi {
+				return true
+			}
+		}
+		for _, r := range e.chars {
+			if c == r {
+				return true
+			}
+		}
+		return false
+	}
+
+	return comb.ParserFunc(func(s comb.Scanner) (comb.Result, comb.Scanner) {
+		c, next, err := s.Next()
+		if err != nil || in(c) {
+			return comb.FailedAt(s, expected), s
+		}
+
+		return s.Result(next), next
+	})
+}
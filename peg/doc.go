@@ -0,0 +1,53 @@
+// Package peg compiles grammars written in a PEG text format into comb
+// parsers.
+//
+// A grammar is a list of rules. Each rule names a parsing expression,
+// and the first rule is the start rule:
+//
+//	# Comments run to the end of the line.
+//	List   <- '[' Items? ']'
+//	Items  <- Item (',' Item)*
+//	Item   <- Number / List
+//	Number <- [0-9]+
+//
+// Rules may be written with "<-", "=", or "::=", and may end with ";".
+// Expressions are built from:
+//
+//	'text' "text"  a literal, with Go escapes, compiled with comb.Token
+//	[a-z_] [^"]    a character class, compiled with comb.Char and comb.CharRange
+//	.              any character
+//	`[0-9]+`       a Go regular expression, compiled with comb.Regexp
+//	Name           a reference to another rule
+//	( e )          grouping
+//	e1 e2          a sequence, compiled with comb.Sequence
+//	e1 / e2        an ordered choice, compiled with comb.Or ("|" also works)
+//	e* e+ e?       repetitions, compiled with comb.Many, comb.OnePlus, and comb.Maybe
+//	&e !e          lookahead, compiled with comb.Peek and comb.Not
+//
+// Whitespace in the input is not skipped automatically, so it must be
+// matched by the grammar. Left recursive rules are supported, using
+// comb.LeftRec. Each rule is wrapped in comb.Label, so errors, traces,
+// and profiles refer to rules by name.
+//
+// The results of a rule are built by the action bound to its name, if
+// any. The action is used as the combiner of each alternative of the rule,
+// and is given a result for each item of the alternative's sequence:
+//
+//	g, err := peg.Compile(`
+//		Sum    <- Sum '+' Number / Number
+//		Number <- [0-9]+
+//	`, peg.Actions{
+//		"Sum": func(results []comb.Result, begin, end comb.Scanner) comb.Result {
+//			if len(results) == 1 {
+//				return results[0]
+//			}
+//			return comb.Result{Interface: results[0].Interface.(int) + results[2].Interface.(int)}
+//		},
+//		"Number": func(results []comb.Result, begin, end comb.Scanner) comb.Result {
+//			n, _ := strconv.Atoi(string(begin.Between(end)))
+//			return comb.Result{Interface: n}
+//		},
+//	})
+//
+// Without actions, sequences and repetitions use comb.SliceCombiner.
+package peg
@@ -0,0 +1,293 @@
+package peg
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/jakebailey/comb"
+)
+
+// grammarParser parses the text of a grammar into its rules.
+var grammarParser comb.TypedParser[[]*rule]
+
+type ident struct {
+	name string
+	pos  comb.Pos
+}
+
+func init() {
+	// Spacing is matched with a regexp, which cannot fail, so that it does
+	// not add whitespace to the expected set of every error.
+	spacing := comb.Text(comb.Regexp(`(?:[ \t\r\n]|#[^\n]*)*`))
+
+	lex := func(p comb.TypedParser[string]) comb.TypedParser[string] {
+		return comb.Seq2(p, spacing, func(v, _ string) string {
+			return v
+		})
+	}
+
+	lexExpr := func(p comb.TypedParser[expr]) comb.TypedParser[expr] {
+		return comb.Seq2(p, spacing, func(e expr, _ string) expr {
+			return e
+		})
+	}
+
+	symbol := func(chars ...rune) comb.TypedParser[string] {
+		return lex(comb.Text(comb.Char(chars...)))
+	}
+
+	name := comb.Seq2(
+		comb.Typed(comb.Label("rule name", comb.Regexp(`[A-Za-z_][A-Za-z0-9_]*`)), func(r comb.Result) ident {
+			return ident{name: r.AsString(), pos: r.Span.Start}
+		}),
+		spacing,
+		func(id ident, _ string) ident {
+			return id
+		},
+	)
+
+	arrow := lex(comb.Text(comb.Or(comb.Token("<-", "::="), comb.Char('='))))
+
+	// A name followed by an arrow starts the next rule, rather than
+	// referring to a rule.
+	notDefinition := comb.Typed(comb.Not(comb.Sequence(nil, name, arrow)), func(comb.Result) string {
+		return ""
+	})
+
+	var expression comb.TypedParser[expr]
+
+	primary := comb.OrOf(
+		comb.Seq2(notDefinition, name, func(_ string, id ident) expr {
+			return &refExpr{name: id.name, pos: id.pos}
+		}),
+		comb.Seq3(symbol('('), comb.ReferenceOf(&expression), symbol(')'), func(_ string, e expr, _ string) expr {
+			return e
+		}),
+		lexExpr(literal()),
+		lexExpr(class()),
+		lexExpr(regexpLiteral()),
+		comb.Map(symbol('.'), func(string) expr {
+			return anyExpr{}
+		}),
+	)
+
+	suffix := comb.Seq2(primary, comb.MaybeOf(symbol('*', '+', '?')), func(e expr, op string) expr {
+		if op == "" {
+			return e
+		}
+		return &repeatExpr{op: op[0], expr: e}
+	})
+
+	prefix := comb.LabelOf("expression", comb.Seq2(comb.MaybeOf(symbol('&', '!')), suffix, func(op string, e expr) expr {
+		if op == "" {
+			return e
+		}
+		return &predicateExpr{op: op[0], expr: e}
+	}))
+
+	sequence := comb.Map(comb.OnePlusOf(prefix), func(items []expr) expr {
+		if len(items) == 1 {
+			return items[0]
+		}
+		return &seqExpr{items: items}
+	})
+
+	expression = comb.Seq2(
+		sequence,
+		comb.ManyOf(comb.Seq2(symbol('/', '|'), sequence, func(_ string, e expr) expr {
+			return e
+		})),
+		func(first expr, rest []expr) expr {
+			if len(rest) == 0 {
+				return first
+			}
+			return &choiceExpr{alts: append([]expr{first}, rest...)}
+		},
+	)
+
+	definition := comb.Seq3(name, arrow, expression, func(id ident, _ string, e expr) *rule {
+		return &rule{name: id.name, pos: id.pos, expr: e}
+	})
+
+	definition = comb.Seq2(definition, comb.MaybeOf(symbol(';')), func(r *rule, _ string) *rule {
+		return r
+	})
+
+	grammarParser = comb.Seq3(
+		spacing,
+		comb.OnePlusOf(definition),
+		comb.Text(comb.EOF()),
+		func(_ string, rules []*rule, _ string) []*rule {
+			return rules
+		},
+	)
+}
+
+// parseGrammar parses the text of a grammar.
+func parseGrammar(src string) ([]*rule, error) {
+	r, _ := comb.Parse(grammarParser, comb.NewUTF8Scanner(src))
+	if !r.Matched() {
+		return nil, syntaxError(r.Err)
+	}
+	return r.Interface.([]*rule), nil
+}
+
+// syntaxError converts a failure to parse a grammar into an *Error.
+func syntaxError(err error) error {
+	var pe *comb.ParseError
+	if errors.As(err, &pe) {
+		return &Error{Pos: pe.Pos(), Msg: pe.Message()}
+	}
+	return err
+}
+
+// literal parses a quoted string literal.
+func literal() comb.TypedParser[expr] {
+	token := comb.Regexp(`'(?:[^'\\\n]|\\.)*'|"(?:[^"\\\n]|\\.)*"`)
+
+	return comb.TypedParserFunc(func(s comb.Scanner) (expr, comb.Result, comb.Scanner) {
+		r, next := token.Parse(s)
+		if !r.Matched() {
+			return nil, r, next
+		}
+
+		quoted := r.AsString()
+		text, err := unquote(quoted[1:len(quoted)-1], quoted[0])
+		if err != nil {
+			return nil, invalid(s, "invalid literal %s: %v", quoted, err), next
+		}
+
+		return &literalExpr{text: text}, r, next
+	})
+}
+
+// class parses a character class.
+func class() comb.TypedParser[expr] {
+	token := comb.Regexp(`\[(?:[^\]\\\n]|\\.)*\]`)
+
+	return comb.TypedParserFunc(func(s comb.Scanner) (expr, comb.Result, comb.Scanner) {
+		r, next := token.Parse(s)
+		if !r.Matched() {
+			return nil, r, next
+		}
+
+		text := r.AsString()
+		e, err := parseClass(text[1 : len(text)-1])
+		if err != nil {
+			return nil, invalid(s, "invalid class %s: %v", text, err), next
+		}
+
+		return e, r, next
+	})
+}
+
+// regexpLiteral parses a regular expression in backquotes.
+func regexpLiteral() comb.TypedParser[expr] {
+	token := comb.Regexp("`[^`]*`")
+
+	return comb.TypedParserFunc(func(s comb.Scanner) (expr, comb.Result, comb.Scanner) {
+		r, next := token.Parse(s)
+		if !r.Matched() {
+			return nil, r, next
+		}
+
+		text := r.AsString()
+		pattern := text[1 : len(text)-1]
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, invalid(s, "invalid regexp %s: %v", text, err), next
+		}
+
+		return &regexpExpr{pattern: pattern}, r, next
+	})
+}
+
+// invalid fails with a committed error for a malformed token at s, so that
+// it is reported rather than any alternatives to it. The error is placed
+// just inside the token, so that it is not replaced by a label at s.
+func invalid(s comb.Scanner, format string, a ...interface{}) comb.Result {
+	if _, next, err := s.Next(); err == nil {
+		s = next
+	}
+
+	r := comb.FailedAtf(s, nil, format, a...)
+	r.Committed = true
+	return r
+}
+
+// unquote decodes the escapes in the body of a literal quoted with quote.
+func unquote(body string, quote byte) (string, error) {
+	var buf []byte
+
+	for len(body) > 0 {
+		c, multibyte, tail, err := strconv.UnquoteChar(body, quote)
+		if err != nil {
+			return "", err
+		}
+		if c < utf8.RuneSelf || !multibyte {
+			buf = append(buf, byte(c))
+		} else {
+			buf = utf8.AppendRune(buf, c)
+		}
+		body = tail
+	}
+
+	return string(buf), nil
+}
+
+// parseClass parses the body of a character class.
+func parseClass(body string) (*classExpr, error) {
+	e := &classExpr{}
+
+	if len(body) > 0 && body[0] == '^' {
+		e.negated = true
+		body = body[1:]
+	}
+
+	next := func() (rune, error) {
+		if body[0] == '\\' && len(body) > 1 {
+			switch body[1] {
+			case ']', '-', '^', '\\':
+				c := rune(body[1])
+				body = body[2:]
+				return c, nil
+			}
+		}
+
+		c, _, tail, err := strconv.UnquoteChar(body, 0)
+		if err != nil {
+			return 0, err
+		}
+		body = tail
+		return c, nil
+	}
+
+	for len(body) > 0 {
+		lo, err := next()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(body) > 1 && body[0] == '-' {
+			body = body[1:]
+			hi, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if hi < lo {
+				return nil, errors.New("range out of order")
+			}
+			e.ranges = append(e.ranges, charRange{lo: lo, hi: hi})
+			continue
+		}
+
+		e.chars = append(e.chars, lo)
+	}
+
+	if len(e.chars) == 0 && len(e.ranges) == 0 {
+		return nil, errors.New("empty class")
+	}
+
+	return e, nil
+}
@@ -0,0 +1,310 @@
+package peg
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/jakebailey/comb"
+	"github.com/stretchr/testify/assert"
+)
+
+// match parses all of input with the start rule of g, returning the text
+// matched or the error.
+func match(g *Grammar, input string) (string, error) {
+	s := comb.NewStringScanner(input)
+
+	r, next := comb.Parse(comb.Sequence(nil, g.Start(), comb.EOF()), s)
+	if !r.Matched() {
+		return "", r.Err
+	}
+
+	return string(s.Between(next)), nil
+}
+
+const listGrammar = `
+# A list of numbers and lists.
+List   <- '[' Items? ']'
+Items  <- Item (',' Item)*
+Item   <- Number / List
+Number <- [0-9]+
+`
+
+func TestCompile(t *testing.T) {
+	g := MustCompile(listGrammar, nil)
+
+	assert.Equal(t, []string{"List", "Items", "Item", "Number"}, g.Rules())
+	assert.NotNil(t, g.Rule("Item"))
+	assert.Nil(t, g.Rule("Missing"))
+
+	for _, input := range []string{"[]", "[1]", "[1,[2,3],[]]"} {
+		t.Run(input, func(t *testing.T) {
+			r, next := comb.Parse(g.Start(), comb.NewStringScanner(input))
+
+			assert.True(t, r.Matched())
+			assert.True(t, next.EOF())
+		})
+	}
+
+	t.Run("failure", func(t *testing.T) {
+		_, err := match(g, "[1,]")
+
+		assert.EqualError(t, err, `line 1 col 4: expected Item but found ']'`)
+	})
+}
+
+func TestCompileSyntax(t *testing.T) {
+	tests := []struct {
+		name    string
+		grammar string
+		matches []string
+		fails   []string
+	}{
+		{
+			name:    "double quotes and escapes",
+			grammar: `A <- "a\tb" '\'' "é"`,
+			matches: []string{"a\tb'é"},
+			fails:   []string{"atb'é"},
+		},
+		{
+			name:    "class",
+			grammar: `A <- [a-c_\]x]+`,
+			matches: []string{"abc_]x"},
+			fails:   []string{"d", "-"},
+		},
+		{
+			name:    "negated class",
+			grammar: `A <- [^"]*`,
+			matches: []string{"", "abc"},
+			fails:   []string{`a"`},
+		},
+		{
+			name:    "negated class with ranges",
+			grammar: `A <- [^0-9x]+`,
+			matches: []string{"abc"},
+			fails:   []string{"a1", "x"},
+		},
+		{
+			name:    "any",
+			grammar: `A <- . .`,
+			matches: []string{"ab", "é!"},
+			fails:   []string{"a", "abc"},
+		},
+		{
+			name:    "regexp",
+			grammar: "A <- `[a-z]+|[0-9]+` ';'",
+			matches: []string{"abc;", "123;"},
+			fails:   []string{"a1;", ";"},
+		},
+		{
+			name:    "predicates",
+			grammar: `A <- !'if' [a-z]+ &';' ';'`,
+			matches: []string{"x;", "iff;"[1:]},
+			fails:   []string{"if;", "iff;"},
+		},
+		{
+			name:    "maybe and one plus",
+			grammar: `A <- 'a'? 'b'+`,
+			matches: []string{"b", "abbb"},
+			fails:   []string{"a", "aab"},
+		},
+		{
+			name:    "alternative syntax",
+			grammar: "A = B | 'c';\nB ::= 'b';",
+			matches: []string{"b", "c"},
+			fails:   []string{"a"},
+		},
+		{
+			name:    "empty literal",
+			grammar: `A <- '' 'a'`,
+			matches: []string{"a"},
+			fails:   []string{""},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g, err := Compile(test.grammar, nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			for _, input := range test.matches {
+				got, err := match(g, input)
+				assert.NoError(t, err, "input %q", input)
+				assert.Equal(t, input, got)
+			}
+
+			for _, input := range test.fails {
+				_, err := match(g, input)
+				assert.Error(t, err, "input %q", input)
+			}
+		})
+	}
+}
+
+func TestCompileUTF8(t *testing.T) {
+	for _, grammar := range []string{`A <- [^0-9x]`, `A <- [^x]`, `A <- [p-r]`, `A <- .`} {
+		t.Run(grammar, func(t *testing.T) {
+			g := MustCompile(grammar, nil)
+
+			r, _ := comb.Parse(g.Start(), comb.NewUTF8Scanner("q"))
+
+			assert.True(t, r.Matched())
+			assert.Equal(t, "q", r.Text)
+			assert.Nil(t, r.Runes)
+		})
+	}
+}
+
+func TestCompileActions(t *testing.T) {
+	number := func(results []comb.Result, begin, end comb.Scanner) comb.Result {
+		n, err := strconv.Atoi(string(begin.Between(end)))
+		if err != nil {
+			return comb.Failed(err)
+		}
+		return comb.Result{Interface: n}
+	}
+
+	// Subtraction is left recursive, so it is left associative.
+	g := MustCompile(`
+		Sub    <- Sub '-' Number / Number
+		Number <- [0-9]+
+	`, Actions{
+		"Sub": func(results []comb.Result, begin, end comb.Scanner) comb.Result {
+			if len(results) == 1 {
+				return results[0]
+			}
+			return comb.Result{Interface: results[0].Interface.(int) - results[2].Interface.(int)}
+		},
+		"Number": number,
+	})
+
+	t.Run("left recursive", func(t *testing.T) {
+		r, next := comb.Parse(g.Start(), comb.NewStringScanner("10-2-3"))
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+		assert.Equal(t, 5, r.Interface)
+	})
+
+	t.Run("sequence", func(t *testing.T) {
+		g := MustCompile(`Pair <- Number ',' Number
+			Number <- [0-9]+`, Actions{
+			"Pair": func(results []comb.Result, begin, end comb.Scanner) comb.Result {
+				assert.Len(t, results, 3)
+				return comb.Result{Interface: results[0].Interface.(int) * results[2].Interface.(int)}
+			},
+			"Number": number,
+		})
+
+		r, _ := comb.Parse(g.Start(), comb.NewStringScanner("6,7"))
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, 42, r.Interface)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		r, _ := comb.Parse(g.Start(), comb.NewStringScanner("99999999999999999999"))
+
+		assert.False(t, r.Matched())
+		assert.Error(t, r.Err)
+	})
+}
+
+func TestCompileLeftRecursion(t *testing.T) {
+	// A and B are indirectly left recursive; C is not left recursive.
+	g := MustCompile(`
+		A <- B 'a' / 'x'
+		B <- A 'b' / C
+		C <- 'c' C?
+	`, nil)
+
+	for _, input := range []string{"x", "xba", "cca", "xbaba"} {
+		got, err := match(g, input)
+		assert.NoError(t, err, "input %q", input)
+		assert.Equal(t, input, got)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		grammar string
+		actions Actions
+		err     string
+	}{
+		{
+			name:    "empty",
+			grammar: "  # nothing\n",
+			err:     `peg: 2:1: expected rule name but found EOF`,
+		},
+		{
+			name:    "missing expression",
+			grammar: "A <- 'a' /\n",
+			err:     `peg: 2:1: expected expression but found EOF`,
+		},
+		{
+			name:    "unclosed group",
+			grammar: "A <- ('a' 'b'\nB <- 'c'",
+			err:     `peg: 2:1: expected one of '*', '+', '?', expression, '/', '|', ')' but found 'B'`,
+		},
+		{
+			name:    "duplicate",
+			grammar: "A <- 'a'\nA <- 'b'",
+			err:     "peg: 2:1: rule A already defined at 1:1",
+		},
+		{
+			name:    "undefined",
+			grammar: "A <- 'a' B",
+			err:     "peg: 1:10: undefined rule B",
+		},
+		{
+			name:    "undefined action",
+			grammar: "A <- 'a'",
+			actions: Actions{"B": comb.SliceCombiner, "C": comb.SliceCombiner},
+			err:     "peg: actions for undefined rules: B, C",
+		},
+		{
+			name:    "invalid escape",
+			grammar: `A <- 'a\q'`,
+			err:     `peg: 1:7: invalid literal 'a\q': invalid syntax`,
+		},
+		{
+			name:    "invalid range",
+			grammar: `A <- [z-a]`,
+			err:     `peg: 1:7: invalid class [z-a]: range out of order`,
+		},
+		{
+			name:    "invalid regexp",
+			grammar: "A <- `a(`",
+			err:     "peg: 1:7: invalid regexp `a(`: error parsing regexp: missing closing ): `a(`",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g, err := Compile(test.grammar, test.actions)
+
+			assert.Nil(t, g)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+
+	t.Run("position", func(t *testing.T) {
+		_, err := Compile("A <- B", nil)
+
+		var pe *Error
+		if assert.True(t, errors.As(err, &pe)) {
+			assert.Equal(t, comb.Pos{Offset: 5, Line: 1, Col: 6}, pe.Pos)
+		}
+	})
+}
+
+func TestMustCompile(t *testing.T) {
+	defer func() {
+		assert.NotNil(t, recover())
+	}()
+
+	MustCompile("A <-", nil)
+}
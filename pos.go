@@ -0,0 +1,52 @@
+package comb
+
+import "fmt"
+
+// Pos is a position in the input. Line and Col are 32 bits, which is
+// plenty for any input, so that a Span, which every Result holds, is small.
+type Pos struct {
+	// Offset is the offset into the input, 0 indexed. This is in runes,
+	// or in bytes for scanners created by NewUTF8Scanner or NewBytesScanner.
+	// If the input is a File, the offset includes the file's base.
+	Offset int
+
+	// Line is the line number, 1 indexed.
+	Line int32
+
+	// Col is the column number in runes, 1 indexed.
+	Col int32
+}
+
+// IsValid returns true if the position is set. The zero Pos is not valid.
+func (p Pos) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position in "line:col" form.
+func (p Pos) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Col)
+}
+
+// Span is a region of the input, in the range [Start, End).
+type Span struct {
+	Start Pos
+	End   Pos
+}
+
+// IsValid returns true if the span is set. The zero Span is not valid.
+func (s Span) IsValid() bool {
+	return s.Start.IsValid()
+}
+
+// Len returns the length of the span, in the same units as Pos.Offset.
+func (s Span) Len() int {
+	return s.End.Offset - s.Start.Offset
+}
+
+// String returns the span in "line:col-line:col" form.
+func (s Span) String() string {
+	return s.Start.String() + "-" + s.End.String()
+}
@@ -0,0 +1,65 @@
+package comb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lineSpan returns the span [start, end) of input on the first line.
+func lineSpan(start, end int) Span {
+	return Span{
+		Start: Pos{Offset: start, Line: 1, Col: int32(start) + 1},
+		End:   Pos{Offset: end, Line: 1, Col: int32(end) + 1},
+	}
+}
+
+func TestPos(t *testing.T) {
+	s := NewStringScanner("ab\ncd")
+
+	assert.Equal(t, Pos{Offset: 0, Line: 1, Col: 1}, s.Pos())
+
+	next := s
+	for i := 0; i < 4; i++ {
+		_, next, _ = next.Next()
+	}
+
+	assert.Equal(t, 4, next.Offset())
+	assert.Equal(t, Pos{Offset: 4, Line: 2, Col: 2}, next.Pos())
+	assert.Equal(t, "2:2", next.Pos().String())
+	assert.False(t, Pos{}.IsValid())
+	assert.Equal(t, "-", Pos{}.String())
+
+	span := s.Span(next)
+	assert.True(t, span.IsValid())
+	assert.Equal(t, 4, span.Len())
+	assert.Equal(t, "1:1-2:2", span.String())
+}
+
+func TestSpan(t *testing.T) {
+	p := Sequence(
+		nil,
+		Tag("key", OnePlusRunes(CharRange('a', 'z'))),
+		Char('='),
+		Maybe(Char('-')),
+		Regexp(`\d+`),
+	)
+	s := NewStringScanner("\nfoo=12")
+
+	_, s, _ = s.Next()
+	r, _ := p.Parse(s)
+
+	assert.True(t, r.Matched())
+
+	at := func(offset int) Pos {
+		return Pos{Offset: offset, Line: 2, Col: int32(offset)}
+	}
+
+	assert.Equal(t, Span{at(1), at(7)}, r.Span)
+
+	results := r.Interface.([]Result)
+	assert.Equal(t, Span{at(1), at(4)}, results[0].Span)
+	assert.Equal(t, Span{at(4), at(5)}, results[1].Span)
+	assert.Equal(t, Span{at(5), at(5)}, results[2].Span)
+	assert.Equal(t, Span{at(5), at(7)}, results[3].Span)
+}
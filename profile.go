@@ -0,0 +1,380 @@
+package comb
+
+import (
+	"compress/gzip"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Profiler collects statistics about the named parsers in a grammar, those
+// wrapped in Tag or Label, over every parse it is attached to with
+// WithProfiler. A Profiler may be shared by concurrent parses.
+type Profiler struct {
+	mu      sync.Mutex
+	rules   map[string]*RuleStats
+	samples map[string]*profileSample
+}
+
+// RuleStats are the statistics collected for a named parser.
+type RuleStats struct {
+	// Name is the tag or label of the parser.
+	Name string
+
+	// Calls is the number of times the parser was run, of which
+	// Matches matched and Failures failed.
+	Calls    int
+	Matches  int
+	Failures int
+
+	// Time is the total time spent running the parser, including the
+	// parsers it ran. Time spent in recursive calls is only counted once.
+	Time time.Duration
+
+	// Rescans is the number of times the parser was run at a position
+	// where it had already been run during the same parse, typically
+	// because an Or backtracked. Rescanned is the total amount of input
+	// scanned by those calls, in the same units as Scanner.Offset.
+	Rescans   int
+	Rescanned int
+}
+
+// profileSample is the cost of a stack of named parsers.
+type profileSample struct {
+	stack []string
+	calls int64
+	self  time.Duration
+}
+
+// NewProfiler creates an empty profiler.
+func NewProfiler() *Profiler {
+	return &Profiler{
+		rules:   make(map[string]*RuleStats),
+		samples: make(map[string]*profileSample),
+	}
+}
+
+// WithProfiler collects statistics about the parse in p.
+func WithProfiler(p *Profiler) Option {
+	return func(st *state) {
+		st.profiler = p
+	}
+}
+
+// Stats returns the statistics for each named parser, sorted by
+// decreasing time.
+func (p *Profiler) Stats() []RuleStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]RuleStats, 0, len(p.rules))
+	for _, rs := range p.rules {
+		stats = append(stats, *rs)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Time != stats[j].Time {
+			return stats[i].Time > stats[j].Time
+		}
+		return stats[i].Name < stats[j].Name
+	})
+
+	return stats
+}
+
+// WriteReport writes a table of the statistics for each named parser to w,
+// sorted by decreasing time.
+func (p *Profiler) WriteReport(w io.Writer) error {
+	rows := [][]string{{"rule", "calls", "matches", "failures", "time", "rescans", "rescanned"}}
+	for _, rs := range p.Stats() {
+		rows = append(rows, []string{
+			rs.Name,
+			strconv.Itoa(rs.Calls),
+			strconv.Itoa(rs.Matches),
+			strconv.Itoa(rs.Failures),
+			rs.Time.String(),
+			strconv.Itoa(rs.Rescans),
+			strconv.Itoa(rs.Rescanned),
+		})
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	// The names are aligned to the left, and the numbers to the right.
+	var b strings.Builder
+	for _, row := range rows {
+		for i, cell := range row {
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			if i == 0 {
+				b.WriteString(cell + pad)
+			} else {
+				b.WriteString("  " + pad + cell)
+			}
+		}
+		b.WriteByte('\n')
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteProfile writes the profile to w in the gzipped protocol buffer
+// format read by pprof, with each named parser as a function:
+//
+//	go tool pprof -top grammar.pprof
+//
+// The profile has two sample types: the number of calls, and the time spent
+// in each parser, not including the named parsers it ran.
+func (p *Profiler) WriteProfile(w io.Writer) error {
+	p.mu.Lock()
+	samples := make([]*profileSample, 0, len(p.samples))
+	for _, sample := range p.samples {
+		samples = append(samples, sample)
+	}
+	p.mu.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool {
+		return strings.Join(samples[i].stack, "\x00") < strings.Join(samples[j].stack, "\x00")
+	})
+
+	var b protoBuffer
+
+	strs := map[string]int64{"": 0}
+	table := []string{""}
+	str := func(s string) int64 {
+		i, ok := strs[s]
+		if !ok {
+			i = int64(len(table))
+			strs[s] = i
+			table = append(table, s)
+		}
+		return i
+	}
+
+	valueType := func(field int, typ, unit string) {
+		var vt protoBuffer
+		vt.int(1, str(typ))
+		vt.int(2, str(unit))
+		b.message(field, &vt)
+	}
+
+	valueType(1, "calls", "count")
+	valueType(1, "time", "nanoseconds")
+
+	ids := make(map[string]uint64)
+	var names []string
+
+	for _, sample := range samples {
+		locations := make([]uint64, len(sample.stack))
+		for i, name := range sample.stack {
+			id, ok := ids[name]
+			if !ok {
+				id = uint64(len(names) + 1)
+				ids[name] = id
+				names = append(names, name)
+			}
+			// Locations are listed from the leaf to the root.
+			locations[len(sample.stack)-1-i] = id
+		}
+
+		var s protoBuffer
+		s.packed(1, locations)
+		s.packed(2, []uint64{uint64(sample.calls), uint64(sample.self)})
+		b.message(2, &s)
+	}
+
+	for i, name := range names {
+		id := uint64(i + 1)
+
+		var line protoBuffer
+		line.uint(1, id)
+
+		var loc protoBuffer
+		loc.uint(1, id)
+		loc.message(4, &line)
+		b.message(4, &loc)
+
+		var fn protoBuffer
+		fn.uint(1, id)
+		fn.int(2, str(name))
+		fn.int(3, str(name))
+		b.message(5, &fn)
+	}
+
+	valueType(11, "time", "nanoseconds")
+	b.int(12, 1)
+	b.int(14, str("time"))
+
+	for _, s := range table {
+		b.bytes(6, []byte(s))
+	}
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(b.buf); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// profileFrame is a named parser running in a profiled parse.
+type profileFrame struct {
+	name     string
+	start    time.Time
+	children time.Duration
+}
+
+// profileKey identifies a named parser at a position.
+type profileKey struct {
+	name   string
+	offset int
+}
+
+// profileEnter records entering the parser named name.
+func (st *state) profileEnter(name string) {
+	if st == nil || st.profiler == nil {
+		return
+	}
+
+	if st.profileActive == nil {
+		st.profileActive = make(map[string]int)
+	}
+	st.profileActive[name]++
+
+	st.profileStack = append(st.profileStack, profileFrame{
+		name:  name,
+		start: time.Now(),
+	})
+}
+
+// profileExit records the result of the parser named name, entered at s.
+func (st *state) profileExit(name string, s, next Scanner, r Result) {
+	if st == nil || st.profiler == nil {
+		return
+	}
+
+	n := len(st.profileStack) - 1
+	frame := st.profileStack[n]
+	st.profileStack = st.profileStack[:n]
+
+	elapsed := time.Since(frame.start)
+	if n > 0 {
+		st.profileStack[n-1].children += elapsed
+	}
+
+	st.profileActive[name]--
+	outermost := st.profileActive[name] == 0
+
+	if st.profileSeen == nil {
+		st.profileSeen = make(map[profileKey]struct{})
+	}
+	key := profileKey{name: name, offset: s.i}
+	_, rescan := st.profileSeen[key]
+	st.profileSeen[key] = struct{}{}
+
+	stack := make([]string, 0, n+1)
+	for _, f := range st.profileStack {
+		stack = append(stack, f.name)
+	}
+	stack = append(stack, name)
+
+	p := st.profiler
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	rs := p.rules[name]
+	if rs == nil {
+		rs = &RuleStats{Name: name}
+		p.rules[name] = rs
+	}
+
+	rs.Calls++
+	if r.Matched() {
+		rs.Matches++
+	} else {
+		rs.Failures++
+	}
+	if outermost {
+		rs.Time += elapsed
+	}
+	if rescan {
+		rs.Rescans++
+		rs.Rescanned += scanned(s, next, r)
+	}
+
+	stackKey := strings.Join(stack, "\x00")
+	sample := p.samples[stackKey]
+	if sample == nil {
+		sample = &profileSample{stack: stack}
+		p.samples[stackKey] = sample
+	}
+	sample.calls++
+	sample.self += elapsed - frame.children
+}
+
+// scanned returns the amount of input scanned by a parser which started
+// at s: up to next if it matched, or up to its error if it failed.
+func scanned(s, next Scanner, r Result) int {
+	if r.Matched() {
+		return next.i - s.i
+	}
+
+	if pe, ok := r.Err.(*ParseError); ok && pe.Offset() > s.i {
+		return pe.Offset() - s.i
+	}
+	return 0
+}
+
+// protoBuffer encodes protocol buffer messages.
+type protoBuffer struct {
+	buf []byte
+}
+
+func (b *protoBuffer) varint(x uint64) {
+	for x >= 0x80 {
+		b.buf = append(b.buf, byte(x)|0x80)
+		x >>= 7
+	}
+	b.buf = append(b.buf, byte(x))
+}
+
+func (b *protoBuffer) key(field, wire int) {
+	b.varint(uint64(field)<<3 | uint64(wire))
+}
+
+func (b *protoBuffer) uint(field int, x uint64) {
+	b.key(field, 0)
+	b.varint(x)
+}
+
+func (b *protoBuffer) int(field int, x int64) {
+	b.uint(field, uint64(x))
+}
+
+func (b *protoBuffer) bytes(field int, data []byte) {
+	b.key(field, 2)
+	b.varint(uint64(len(data)))
+	b.buf = append(b.buf, data...)
+}
+
+func (b *protoBuffer) message(field int, m *protoBuffer) {
+	b.bytes(field, m.buf)
+}
+
+func (b *protoBuffer) packed(field int, xs []uint64) {
+	var p protoBuffer
+	for _, x := range xs {
+		p.varint(x)
+	}
+	b.bytes(field, p.buf)
+}
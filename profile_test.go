@@ -0,0 +1,195 @@
+package comb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfiler(t *testing.T) {
+	item := Tag("item", OnePlusRunes(CharRange('a', 'z')))
+	p := Label("list", Or(
+		Sequence(nil, item, Char('!')),
+		Sequence(nil, item, Char('?')),
+	))
+
+	prof := NewProfiler()
+
+	r, _ := Parse(p, NewStringScanner("ab?"), WithProfiler(prof))
+	assert.True(t, r.Matched())
+
+	r, _ = Parse(p, NewStringScanner("1"), WithProfiler(prof))
+	assert.False(t, r.Matched())
+
+	stats := prof.Stats()
+	assert.Len(t, stats, 2)
+
+	byName := make(map[string]RuleStats)
+	for _, rs := range stats {
+		assert.True(t, rs.Time > 0)
+		rs.Time = 0
+		byName[rs.Name] = rs
+	}
+
+	assert.Equal(t, RuleStats{
+		Name:      "item",
+		Calls:     4,
+		Matches:   2,
+		Failures:  2,
+		Rescans:   2,
+		Rescanned: 2,
+	}, byName["item"])
+
+	assert.Equal(t, RuleStats{
+		Name:     "list",
+		Calls:    2,
+		Matches:  1,
+		Failures: 1,
+	}, byName["list"])
+
+	t.Run("report", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		assert.NoError(t, prof.WriteReport(&buf))
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		assert.Len(t, lines, 3)
+		assert.Equal(t, []string{"rule", "calls", "matches", "failures", "time", "rescans", "rescanned"}, strings.Fields(lines[0]))
+		assert.True(t, strings.HasPrefix(lines[1], "list  "))
+		assert.True(t, strings.HasPrefix(lines[2], "item  "))
+		for _, line := range lines {
+			assert.Equal(t, utf8.RuneCountInString(lines[0]), utf8.RuneCountInString(line))
+		}
+		assert.True(t, strings.HasSuffix(lines[0], "  rescanned"))
+		assert.True(t, strings.HasSuffix(lines[2], strings.Repeat(" ", len("rescanned")-1)+"2"))
+	})
+
+	t.Run("pprof", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		assert.NoError(t, prof.WriteProfile(&buf))
+
+		zr, err := gzip.NewReader(&buf)
+		assert.NoError(t, err)
+
+		data, err := ioutil.ReadAll(zr)
+		assert.NoError(t, err)
+
+		profile := decodeProto(t, data)
+
+		var strs []string
+		for _, f := range profile[6] {
+			strs = append(strs, string(f.bytes))
+		}
+
+		var types []string
+		for _, f := range profile[1] {
+			vt := decodeProto(t, f.bytes)
+			types = append(types, strs[vt[1][0].varint]+"/"+strs[vt[2][0].varint])
+		}
+		assert.Equal(t, []string{"calls/count", "time/nanoseconds"}, types)
+
+		functions := make(map[uint64]string)
+		for _, f := range profile[5] {
+			fn := decodeProto(t, f.bytes)
+			functions[fn[1][0].varint] = strs[fn[2][0].varint]
+		}
+
+		locations := make(map[uint64]string)
+		for _, f := range profile[4] {
+			loc := decodeProto(t, f.bytes)
+			line := decodeProto(t, loc[4][0].bytes)
+			locations[loc[1][0].varint] = functions[line[1][0].varint]
+		}
+
+		calls := make(map[string]uint64)
+		for _, f := range profile[2] {
+			sample := decodeProto(t, f.bytes)
+			stack := decodePacked(t, sample[1][0].bytes)
+			values := decodePacked(t, sample[2][0].bytes)
+
+			if assert.Len(t, values, 2) {
+				calls[locations[stack[0]]] += values[0]
+			}
+		}
+		assert.Equal(t, map[string]uint64{"list": 2, "item": 4}, calls)
+		assert.Len(t, profile[2], 2)
+	})
+
+	t.Run("not profiled", func(t *testing.T) {
+		before := prof.Stats()
+
+		r, _ := Parse(p, NewStringScanner("ab!"))
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, len(before), len(prof.Stats()))
+		for i, rs := range prof.Stats() {
+			assert.Equal(t, before[i].Calls, rs.Calls)
+		}
+	})
+}
+
+type protoField struct {
+	varint uint64
+	bytes  []byte
+}
+
+// decodeProto decodes the fields of a protocol buffer message by number.
+func decodeProto(t *testing.T, data []byte) map[int][]protoField {
+	fields := make(map[int][]protoField)
+
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if !assert.True(t, n > 0) {
+			return fields
+		}
+		data = data[n:]
+
+		x, n := binary.Uvarint(data)
+		if !assert.True(t, n > 0) {
+			return fields
+		}
+		data = data[n:]
+
+		f := protoField{varint: x}
+		switch key & 7 {
+		case 0:
+		case 2:
+			f.bytes, data = data[:x], data[x:]
+		default:
+			t.Fatalf("unexpected wire type %d", key&7)
+		}
+
+		fields[int(key>>3)] = append(fields[int(key>>3)], f)
+	}
+
+	return fields
+}
+
+func decodePacked(t *testing.T, data []byte) []uint64 {
+	var xs []uint64
+	for len(data) > 0 {
+		x, n := binary.Uvarint(data)
+		if !assert.True(t, n > 0) {
+			return xs
+		}
+		xs = append(xs, x)
+		data = data[n:]
+	}
+	return xs
+}
+
+func TestProtoBuffer(t *testing.T) {
+	var b protoBuffer
+	b.uint(1, 150)
+	b.bytes(2, []byte("hi"))
+	b.packed(3, []uint64{1, 300})
+
+	assert.Equal(t, []byte{0x08, 0x96, 0x01, 0x12, 0x02, 'h', 'i', 0x1a, 0x03, 0x01, 0xac, 0x02}, b.buf)
+}
@@ -0,0 +1,77 @@
+package comb
+
+// Recover allows parsing to continue after a parser fails. If the parser
+// fails, its error is collected, and input is skipped from the original
+// position until sync matches. The input matched by sync is also consumed.
+// If sync never matches, the rest of the input is skipped. If no input
+// can be skipped at all, as at EOF, the failure is returned as-is.
+//
+// In place of the failed result, Recover returns a placeholder result with
+// the skipped input in Runes or Text and the collected error in Interface.
+//
+// An error is only kept if the parse commits to the branch Recover is in.
+// If an enclosing parser such as Or backtracks and tries an alternative,
+// the errors recovered within the failed branch are dropped.
+//
+// Errors can only be collected when parsing with ParseAll. Otherwise,
+// Recover returns the failure unchanged.
+//
+//	stmts := comb.Many(nil, comb.Recover(stmt, comb.Char(';')))
+func Recover(parser, sync Parser) Parser {
+	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		if s.st == nil || !s.st.recover {
+			return parser.Parse(s)
+		}
+
+		mark := s.st.begin()
+		pin := s.st.pin(s)
+
+		r, next := parser.Parse(s)
+		if r.Matched() {
+			s.st.end(mark, nil)
+			s.st.unpin(pin)
+			return r, next
+		}
+
+		r = s.st.fail(mark, r)
+		if s.st.stopped != nil {
+			s.st.unpin(pin)
+			return r, next
+		}
+
+		next = s
+		for {
+			sr, after := sync.Parse(next)
+			if sr.Matched() {
+				next = after
+				break
+			}
+
+			var err error
+			if _, after, err = next.Next(); err != nil {
+				break
+			}
+			next = after
+		}
+
+		// Recovering without skipping anything would allow repetitions
+		// such as Many to loop forever, so fail instead.
+		if next.i == s.i {
+			s.st.unpin(pin)
+			return r, s
+		}
+
+		// The skipped input should not count towards later failures, and
+		// the errors recovered within the failed parser are replaced by its
+		// own. The error is kept only if the parse does not backtrack from
+		// the branch Recover is in.
+		s.st.discard(mark)
+		keepFound(r.Err)
+		s.st.errors = append(s.st.errors, r.Err)
+
+		skipped := s.Result(next)
+		skipped.Interface = r.Err
+		s.st.unpin(pin)
+		return skipped, next
+	})
+}
@@ -0,0 +1,108 @@
+package comb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover(t *testing.T) {
+	stmt := SequenceRunes(CharRange('a', 'z'), Char('='), CharRange('0', '9'), Char(';'))
+	p := Sequence(
+		nil,
+		Many(nil, Recover(stmt, Char(';'))),
+		EOF(),
+	)
+
+	t.Run("no errors", func(t *testing.T) {
+		s := NewStringScanner("a=1;b=2;")
+
+		r, next, errs := ParseAll(p, s)
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+		assert.Empty(t, errs)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		s := NewStringScanner("a=1;b=x;c=3;d4;")
+
+		r, next, errs := ParseAll(p, s)
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+
+		if assert.Len(t, errs, 2) {
+			assert.EqualError(t, errs[0], "line 1 col 7: expected '0'-'9' but found 'x'")
+			assert.Equal(t, 6, errs[0].(*ParseError).Offset())
+			assert.EqualError(t, errs[1], "line 1 col 14: expected '=' but found '4'")
+			assert.Equal(t, 13, errs[1].(*ParseError).Offset())
+		}
+
+		stmts := r.Interface.([]Result)[0].Interface.([]Result)
+		if assert.Len(t, stmts, 4) {
+			assert.Equal(t, []rune("a=1;"), stmts[0].Runes)
+			assert.Equal(t, []rune("b=x;"), stmts[1].Runes)
+			assert.Equal(t, errs[0], stmts[1].Interface)
+			assert.Equal(t, []rune("c=3;"), stmts[2].Runes)
+			assert.Equal(t, []rune("d4;"), stmts[3].Runes)
+		}
+	})
+
+	t.Run("no sync", func(t *testing.T) {
+		s := NewStringScanner("a=1;b=")
+
+		r, next, errs := ParseAll(p, s)
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+
+		if assert.Len(t, errs, 1) {
+			assert.Equal(t, 6, errs[0].(*ParseError).Offset())
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		p := Sequence(nil, Recover(stmt, Char(';')), Char('!'))
+		s := NewStringScanner("a=;b")
+
+		r, _, errs := ParseAll(p, s)
+
+		assert.False(t, r.Matched())
+
+		if assert.Len(t, errs, 2) {
+			assert.Equal(t, 2, errs[0].(*ParseError).Offset())
+			assert.Equal(t, r.Err, errs[1])
+			assert.Equal(t, 3, errs[1].(*ParseError).Offset())
+		}
+	})
+
+	t.Run("backtracked", func(t *testing.T) {
+		p := Or(Sequence(nil, Recover(stmt, Char(';')), Char('!')), Token("a=;b"))
+		s := NewStringScanner("a=;b")
+
+		r, next, errs := ParseAll(p, s)
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+		assert.Empty(t, errs)
+	})
+
+	t.Run("backtracked by Maybe", func(t *testing.T) {
+		p := Sequence(nil, Maybe(Sequence(nil, Recover(stmt, Char(';')), Char('!'))), Token("a=;b"))
+		s := NewStringScanner("a=;b")
+
+		r, _, errs := ParseAll(p, s)
+
+		assert.True(t, r.Matched())
+		assert.Empty(t, errs)
+	})
+
+	t.Run("without ParseAll", func(t *testing.T) {
+		s := NewStringScanner("a=x;")
+
+		r, _ := p.Parse(s)
+
+		assert.False(t, r.Matched())
+	})
+}
@@ -1,27 +1,43 @@
 package comb
 
 import (
+	"fmt"
 	"regexp"
 	"unicode/utf8"
 )
 
-// Regexp compiles a Go regexp into a parser. If the pattern does
-// not begin with ^, one will be added, as the parser must begin
-// with the next rune.
+// Regexp compiles a Go regexp into a parser. The pattern is anchored
+// as if it were written ^(?:pattern), as the match must begin with the
+// next rune, even if the pattern has alternatives.
 func Regexp(pattern string) Parser {
-	realPattern := pattern
-	if realPattern == "" || realPattern[0] != '^' {
-		realPattern = "^" + realPattern
-	}
-
-	re := regexp.MustCompile(realPattern)
+	re := regexp.MustCompile("^(?:" + pattern + ")")
+	expected := []string{fmt.Sprintf("text matching %q", pattern)}
 
 	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		var match []int
+
+		if s.text != "" {
+			// UTF-8 scanners can be matched directly, and the
+			// match is a byte offset just like the scanner's.
+			match = re.FindStringIndex(s.text[s.i:])
+			if match == nil {
+				return FailedAt(s, expected...), s
+			}
+
+			end := s.i + match[1]
+			next := s
+			for next.i < end {
+				_, next, _ = next.Next()
+			}
+
+			return s.Result(next), next
+		}
+
 		sr := &scannerReader{s}
 
-		match := re.FindReaderIndex(sr)
+		match = re.FindReaderIndex(sr)
 		if match == nil {
-			return Failedf("regexp %q did not match", pattern), s
+			return FailedAt(s, expected...), s
 		}
 
 		var r rune
@@ -33,7 +49,7 @@ func Regexp(pattern string) Parser {
 		for count > 0 {
 			r, next, err = next.Next()
 			if err != nil {
-				return Failed(err), next
+				return failedCause(next, expected, err), next
 			}
 
 			count -= utf8.RuneLen(r)
@@ -43,9 +59,7 @@ func Regexp(pattern string) Parser {
 			panic("bug: got more bytes than regexp match specified")
 		}
 
-		return Result{
-			Runes: s.Between(next),
-		}, next
+		return s.Result(next), next
 	})
 }
 
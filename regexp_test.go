@@ -1,6 +1,7 @@
 package comb
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,6 +17,7 @@ func TestRegexp(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune("Hello, 世界"),
+			Span:  lineSpan(0, 9),
 		}
 
 		assert.True(t, r.Matched())
@@ -29,7 +31,7 @@ func TestRegexp(t *testing.T) {
 		r, _ := p.Parse(s)
 
 		assert.False(t, r.Matched())
-		assert.EqualError(t, r.Err, `regexp "[^\\.]+" did not match`)
+		assert.EqualError(t, r.Err, `line 1 col 1: expected text matching "[^\\.]+" but found '.'`)
 	})
 
 	t.Run("empty", func(t *testing.T) {
@@ -40,12 +42,55 @@ func TestRegexp(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune{},
+			Span:  lineSpan(0, 0),
 		}
 
 		assert.True(t, r.Matched())
 		assert.Equal(t, expected, r)
 		assert.False(t, next.EOF())
 	})
+
+	t.Run("UTF-8", func(t *testing.T) {
+		s := NewUTF8Scanner("Hello,\n世界.")
+
+		r, next := p.Parse(s)
+
+		expected := Result{
+			Text: "Hello,\n世界",
+			Span: Span{
+				Start: Pos{Offset: 0, Line: 1, Col: 1},
+				End:   Pos{Offset: 13, Line: 2, Col: 3},
+			},
+		}
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, expected, r)
+		assert.Equal(t, 13, next.i)
+		assert.Equal(t, 2, next.Line())
+		assert.Equal(t, 3, next.Col())
+
+		r, _ = p.Parse(next)
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, `line 2 col 3: expected text matching "[^\\.]+" but found '.'`)
+	})
+
+	t.Run("alternatives", func(t *testing.T) {
+		p := Regexp("a|b")
+
+		for _, s := range []Scanner{NewStringScanner("xxb"), NewUTF8Scanner("xxb")} {
+			r, next := p.Parse(s)
+
+			assert.False(t, r.Matched())
+			assert.Equal(t, 0, next.Offset())
+		}
+
+		r, next := p.Parse(NewUTF8Scanner("bx"))
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, "b", r.Text)
+		assert.Equal(t, 1, next.Offset())
+	})
 }
 
 func BenchmarkRegexp(b *testing.B) {
@@ -71,3 +116,35 @@ func BenchmarkRegexpManyTokens(b *testing.B) {
 		p.Parse(s)
 	}
 }
+
+func BenchmarkRegexpUTF8(b *testing.B) {
+	b.ReportAllocs()
+
+	p := Regexp(`[^\.]+`)
+	s := NewUTF8Scanner("Hello, 世界.")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Parse(s)
+	}
+}
+
+func benchmarkRegexpLines(b *testing.B, newScanner func(string) Scanner) {
+	b.ReportAllocs()
+
+	p := Many(nil, Regexp(`[^\n]*\n`))
+	input := strings.Repeat("The quick brown fox jumps over the lazy dog.\n", 1000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Parse(newScanner(input))
+	}
+}
+
+func BenchmarkRegexpLinesRunes(b *testing.B) {
+	benchmarkRegexpLines(b, NewStringScanner)
+}
+
+func BenchmarkRegexpLinesUTF8(b *testing.B) {
+	benchmarkRegexpLines(b, NewUTF8Scanner)
+}
@@ -5,15 +5,27 @@ package comb
 // integer and float types used in strconv, as well as an interface{}
 // for anything not included. Err will be set if a Result is failed.
 // If your result contains an error that is not a failure, then it should
-// be placed into Interface.
+// be placed into Interface. Committed marks a failure as final; see Commit.
+//
+// Builtin parsers capture the input they match in Runes, or in Text for
+// scanners created with NewUTF8Scanner or NewBytesScanner, so that the
+// input is not copied. AsString and AsRunes return the input captured in
+// either form.
+//
+// Span is the region of input matched. It is set by all builtin parsers,
+// and by combining parsers such as Sequence and Many if the combiner
+// does not set it.
 type Result struct {
 	Err       error
 	Runes     []rune
+	Text      string
 	Int64     int64
 	Float64   float64
 	Interface interface{}
 	Tag       string
 	Ignore    bool
+	Committed bool
+	Span      Span
 }
 
 // Matched returns true if Err is not nil.
@@ -21,6 +33,24 @@ func (r Result) Matched() bool {
 	return r.Err == nil
 }
 
+// AsString returns the input captured by a result, from Text if it is set,
+// otherwise from Runes.
+func (r Result) AsString() string {
+	if r.Text != "" {
+		return r.Text
+	}
+	return string(r.Runes)
+}
+
+// AsRunes returns the input captured by a result, from Runes if it is set,
+// otherwise decoding Text into a new slice.
+func (r Result) AsRunes() []rune {
+	if r.Text != "" {
+		return []rune(r.Text)
+	}
+	return r.Runes
+}
+
 // Failed returns a failed result with a given error.
 func Failed(err error) Result {
 	return Result{Err: err}
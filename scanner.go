@@ -1,33 +1,86 @@
 package comb
 
-import "io"
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+)
 
-// Scanner is an immutable struct which scans over a rune slice.
+// Scanner is an immutable struct which scans over a rune slice,
+// UTF-8 text, or the runes read from an io.Reader. A Scanner is only
+// a position and a pointer to its input, so it is cheap to copy.
 type Scanner struct {
-	runes []rune
-	i     int
-	line  int
-	col   int
+	*input
+	i    int
+	line int32
+	col  int32
+}
+
+// input is shared by every Scanner over the same input. It is never
+// modified; a scanner with different input, such as one given a File or
+// the state of a parse, points to a copy.
+type input struct {
+	runes  []rune
+	text   string
+	stream *runeStream
+	file   *File
+	st     *state
 }
 
 // NewScanner creates a new Scanner from a rune slice.
 func NewScanner(s []rune) Scanner {
-	return Scanner{runes: s}
+	return Scanner{input: &input{runes: s}}
 }
 
 // NewStringScanner creates a new Scanner from a string.
 func NewStringScanner(s string) Scanner {
-	return Scanner{runes: []rune(s)}
+	return Scanner{input: &input{runes: []rune(s)}}
+}
+
+// NewUTF8Scanner creates a new Scanner which decodes runes from a UTF-8
+// string as they are scanned, rather than converting the whole string to
+// a rune slice up front. Positions in the scanner are byte offsets into s,
+// and BetweenString returns substrings of s without copying. Invalid UTF-8
+// is scanned as utf8.RuneError, one byte at a time.
+func NewUTF8Scanner(s string) Scanner {
+	return Scanner{input: &input{text: s}}
+}
+
+// NewBytesScanner creates a new Scanner over UTF-8 encoded bytes, like
+// NewUTF8Scanner. The bytes are not copied, so b must not be modified
+// while the scanner, or any Text or BetweenString from it, is in use.
+func NewBytesScanner(b []byte) Scanner {
+	return Scanner{input: &input{text: unsafe.String(unsafe.SliceData(b), len(b))}}
 }
 
 // Next scans for the next rune, returning the rune and the next Scanner.
-// If there are no more runes to scan, io.EOF is returned.
+// If there are no more runes to scan, io.EOF is returned. For a scanner
+// created with NewReaderScanner, any other error from the reader is
+// returned as well.
 func (s Scanner) Next() (rune, Scanner, error) {
-	if s.EOF() {
-		return 0, s, io.EOF
-	}
+	var r rune
+	var size int
 
-	r := s.runes[s.i]
+	if s.stream != nil {
+		if err := s.fill(); err != nil {
+			return 0, s, err
+		}
+		r = s.stream.buf[s.i-s.stream.base]
+		size = 1
+	} else if s.text != "" {
+		if s.i >= len(s.text) {
+			return 0, s, io.EOF
+		}
+		r, size = utf8.DecodeRuneInString(s.text[s.i:])
+	} else {
+		if s.i >= len(s.runes) {
+			return 0, s, io.EOF
+		}
+		r = s.runes[s.i]
+		size = 1
+	}
 
 	col := s.col
 	line := s.line
@@ -40,8 +93,8 @@ func (s Scanner) Next() (rune, Scanner, error) {
 	}
 
 	return r, Scanner{
-		runes: s.runes,
-		i:     s.i + 1,
+		input: s.input,
+		i:     s.i + size,
 		line:  line,
 		col:   col,
 	}, nil
@@ -50,21 +103,159 @@ func (s Scanner) Next() (rune, Scanner, error) {
 // EOF returns true if the scanner is at EOF, i.e. a call to Next would
 // return EOF.
 func (s Scanner) EOF() bool {
+	if s.stream != nil {
+		return s.fill() != nil
+	}
+	if s.text != "" {
+		return s.i >= len(s.text)
+	}
 	return s.i >= len(s.runes)
 }
 
+// fill buffers the rune at s from its stream. If the input is larger than
+// the parse allows, the parse is stopped.
+func (s Scanner) fill() error {
+	err := s.stream.fill(s.i)
+	if le, ok := err.(*LimitError); ok && s.st != nil && s.st.stopped == nil {
+		s.st.stop(s, le)
+	}
+	return err
+}
+
 // Between returns the slice between two scanners.
 // s1.Between(s2) returns a slice in the range [s1, s2).
+// For scanners created with NewUTF8Scanner or NewBytesScanner,
+// the runes must be decoded into a new slice; use BetweenString
+// or Result to avoid the copy.
 func (s Scanner) Between(other Scanner) []rune {
+	if s.stream != nil {
+		return s.stream.slice(s.i, other.i)
+	}
+	if s.text != "" {
+		return []rune(s.text[s.i:other.i])
+	}
 	return s.runes[s.i:other.i]
 }
 
+// BetweenString is like Between, but returns a string. For scanners
+// created with NewUTF8Scanner or NewBytesScanner, this is a substring
+// of the original text, and does not allocate.
+func (s Scanner) BetweenString(other Scanner) string {
+	if s.text != "" {
+		return s.text[s.i:other.i]
+	}
+	return string(s.Between(other))
+}
+
+// Result returns a matched result holding the input between two scanners,
+// as builtin parsers do. s1.Result(s2) holds the input in the range
+// [s1, s2) in Runes, or for scanners created with NewUTF8Scanner or
+// NewBytesScanner, in Text, which is a substring of the original text.
+func (s Scanner) Result(other Scanner) Result {
+	if s.text != "" {
+		return Result{
+			Text: s.text[s.i:other.i],
+			Span: s.Span(other),
+		}
+	}
+
+	return Result{
+		Runes: s.Between(other),
+		Span:  s.Span(other),
+	}
+}
+
+// Span returns the span between two scanners.
+// s1.Span(s2) returns the span [s1, s2).
+func (s Scanner) Span(other Scanner) Span {
+	return Span{
+		Start: s.Pos(),
+		End:   other.Pos(),
+	}
+}
+
+// Offset returns the current offset into the input, 0 indexed. This is in
+// runes, or in bytes for scanners created by NewUTF8Scanner or
+// NewBytesScanner.
+func (s Scanner) Offset() int {
+	return s.i
+}
+
+// Pos returns the current position. If the scanner belongs to a File,
+// the offset of the position includes the file's base.
+func (s Scanner) Pos() Pos {
+	offset := s.i
+	if s.file != nil {
+		offset += s.file.base
+	}
+
+	return Pos{
+		Offset: offset,
+		Line:   s.line + 1,
+		Col:    s.col + 1,
+	}
+}
+
+// WithFile returns a copy of the scanner whose positions belong to f.
+// The scanner should be at the beginning of its input.
+func (s Scanner) WithFile(f *File) Scanner {
+	in := *s.input
+	in.file = f
+	s.input = &in
+	return s
+}
+
+// File returns the file the scanner belongs to, or nil.
+func (s Scanner) File() *File {
+	return s.file
+}
+
+// position describes the current position for messages, in "name:line:col"
+// form if the scanner belongs to a file, otherwise "line L col C".
+func (s Scanner) position() string {
+	if s.file != nil {
+		return s.file.Position(s.Pos())
+	}
+	return fmt.Sprintf("line %d col %d", s.Line(), s.Col())
+}
+
 // Line returns the current line number, 1 indexed.
 func (s Scanner) Line() int {
-	return s.line + 1
+	return int(s.line) + 1
 }
 
 // Col returns the current column number, 1 indexed.
 func (s Scanner) Col() int {
-	return s.col + 1
+	return int(s.col) + 1
+}
+
+// around returns the runes of the line the scanner is on, preceded by up
+// to n lines before it if they are still available, along with the index
+// of the scanner's position in the returned runes.
+func (s Scanner) around(n int) ([]rune, int) {
+	if s.text != "" {
+		start := 0
+		for l, end := 0, s.i; l <= n; l++ {
+			nl := strings.LastIndexByte(s.text[:end], '\n')
+			if nl < 0 {
+				start = 0
+				break
+			}
+			start, end = nl+1, nl
+		}
+
+		end := s.i + strings.IndexByte(s.text[s.i:], '\n')
+		if end < s.i {
+			end = len(s.text)
+		}
+
+		return []rune(s.text[start:end]), utf8.RuneCountInString(s.text[start:s.i])
+	}
+
+	if s.stream != nil {
+		rs := s.stream
+		return rs.buf[rs.min-rs.base:], s.i - rs.min
+	}
+
+	return s.runes, s.i
 }
@@ -2,7 +2,9 @@ package comb
 
 import (
 	"io"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -94,3 +96,134 @@ func BenchmarkScanner(b *testing.B) {
 		}
 	}
 }
+
+func TestUTF8Scanner(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		str := "Hello,\n世界"
+		runes := []rune(str)
+		s := NewUTF8Scanner(str)
+
+		var r rune
+		next := s
+		var err error
+
+		for i := 0; i < len(runes); i++ {
+			assert.False(t, next.EOF())
+			r, next, err = next.Next()
+			assert.Equal(t, runes[i], r)
+			assert.Nil(t, err)
+		}
+
+		assert.True(t, next.EOF())
+		_, _, err = next.Next()
+		assert.Equal(t, io.EOF, err)
+
+		assert.Equal(t, len(str), next.i)
+		assert.Equal(t, 2, next.Line())
+		assert.Equal(t, 3, next.Col())
+		assert.Equal(t, runes, s.Between(next))
+		assert.Equal(t, str, s.BetweenString(next))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		s := NewBytesScanner([]byte{'a', 0xff, 'b'})
+
+		r, next, err := s.Next()
+		assert.Equal(t, 'a', r)
+		assert.Nil(t, err)
+
+		r, next, err = next.Next()
+		assert.Equal(t, utf8.RuneError, r)
+		assert.Nil(t, err)
+
+		r, next, err = next.Next()
+		assert.Equal(t, 'b', r)
+		assert.Nil(t, err)
+		assert.True(t, next.EOF())
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		p := Sequence(
+			nil,
+			Token("世界"),
+			OnePlusRunes(Char('!')),
+		)
+		s := NewUTF8Scanner("世界!!?")
+
+		r, next := p.Parse(s)
+
+		expected := Result{
+			Interface: []Result{
+				{Text: "世界", Span: Span{Start: Pos{0, 1, 1}, End: Pos{6, 1, 3}}},
+				{Text: "!!", Span: Span{Start: Pos{6, 1, 3}, End: Pos{8, 1, 5}}},
+			},
+			Span: Span{Start: Pos{0, 1, 1}, End: Pos{8, 1, 5}},
+		}
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, expected, r)
+		assert.Equal(t, "世界!!", s.BetweenString(next))
+		assert.Equal(t, []rune("世界"), expected.Interface.([]Result)[0].AsRunes())
+	})
+
+	t.Run("no copy", func(t *testing.T) {
+		b := []byte("abc")
+		s := NewBytesScanner(b)
+
+		_, next, _ := s.Next()
+		r := s.Result(next)
+
+		b[0] = 'x'
+		assert.Equal(t, "x", r.Text)
+		assert.Equal(t, "x", r.AsString())
+		assert.Zero(t, testing.AllocsPerRun(10, func() {
+			s.Result(next)
+		}))
+	})
+
+	t.Run("FormatError", func(t *testing.T) {
+		s := NewUTF8Scanner("a\nb\n世界x\nc")
+
+		r, next := Sequence(nil, Token("a\nb\n世界"), Char('y')).Parse(s)
+
+		expected := "line 3 col 3: expected 'y' but found 'x'\n" +
+			" 2 | b\n" +
+			" 3 | 世界x\n" +
+			"   |   ^\n"
+
+		assert.Equal(t, expected, FormatError(r, next, &ErrorFormat{Context: 1}))
+	})
+}
+
+func BenchmarkNewStringScanner(b *testing.B) {
+	b.ReportAllocs()
+
+	input := strings.Repeat("The quick brown fox jumps over the lazy dog.\n", 1000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewStringScanner(input)
+	}
+}
+
+func BenchmarkNewUTF8Scanner(b *testing.B) {
+	b.ReportAllocs()
+
+	input := strings.Repeat("The quick brown fox jumps over the lazy dog.\n", 1000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewUTF8Scanner(input)
+	}
+}
+
+func BenchmarkNewBytesScanner(b *testing.B) {
+	b.ReportAllocs()
+
+	input := []byte(strings.Repeat("The quick brown fox jumps over the lazy dog.\n", 1000))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewBytesScanner(input)
+	}
+}
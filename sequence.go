@@ -7,11 +7,24 @@ package comb
 //
 // If you only need the runes captured by Sequence, use SequenceRunes instead.
 func Sequence(combiner ResultCombiner, parsers ...Parser) Parser {
+	return sequence(combiner, combiner != nil, parsers)
+}
+
+// sequence builds a Sequence. If pinned is true, the input matched is kept
+// buffered for the combiner, which may read it.
+func sequence(combiner ResultCombiner, pinned bool, parsers []Parser) Parser {
 	if combiner == nil {
 		combiner = SliceCombiner
 	}
 
+	cut := cutIndex(parsers)
+
 	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		mark := s.st.begin()
+		pin := -1
+		if pinned {
+			pin = s.st.pin(s)
+		}
 		var results []Result
 
 		var r Result
@@ -21,7 +34,15 @@ func Sequence(combiner ResultCombiner, parsers ...Parser) Parser {
 			r, next = p.Parse(next)
 
 			if !r.Matched() {
-				return r, next
+				if i > cut {
+					r.Committed = true
+				}
+				s.st.unpin(pin)
+				return s.st.fail(mark, r), next
+			}
+
+			if i == cut {
+				s.st.commit(next)
 			}
 
 			if results == nil {
@@ -31,7 +52,10 @@ func Sequence(combiner ResultCombiner, parsers ...Parser) Parser {
 			results[i] = r
 		}
 
-		return combiner(results, s, next), next
+		s.st.end(mark, nil)
+		r = combine(combiner, results, s, next)
+		s.st.unpin(pin)
+		return r, next
 	})
 }
 
@@ -39,6 +63,16 @@ func Sequence(combiner ResultCombiner, parsers ...Parser) Parser {
 // and surrounding scanners and combines them into a single result.
 type ResultCombiner func(results []Result, begin, end Scanner) Result
 
+// combine calls a combiner, setting the span of its result
+// if the combiner did not.
+func combine(combiner ResultCombiner, results []Result, begin, end Scanner) Result {
+	r := combiner(results, begin, end)
+	if !r.Span.IsValid() {
+		r.Span = begin.Span(end)
+	}
+	return r
+}
+
 // SliceCombiner combines results by returning a Result with the
 // slice in Interface. If a result is set to be ignored, the result
 // will not be in the new result slice.
@@ -66,21 +100,33 @@ func SliceCombiner(results []Result, begin, end Scanner) Result {
 // SequenceRunes does not read any results, just the returned scanners,
 // so cannot respect the Ignored option.
 func SequenceRunes(parsers ...Parser) Parser {
+	cut := cutIndex(parsers)
+
 	return ParserFunc(func(s Scanner) (Result, Scanner) {
+		mark := s.st.begin()
+		pin := s.st.pin(s)
 		var r Result
 		next := s
 
-		for _, p := range parsers {
+		for i, p := range parsers {
 			r, next = p.Parse(next)
 
 			if !r.Matched() {
-				return r, next
+				if i > cut {
+					r.Committed = true
+				}
+				s.st.unpin(pin)
+				return s.st.fail(mark, r), next
+			}
+
+			if i == cut {
+				s.st.commit(next)
 			}
 		}
 
-		return Result{
-			Runes: s.Between(next),
-		}, next
+		s.st.end(mark, nil)
+		s.st.unpin(pin)
+		return s.Result(next), next
 	})
 }
 
@@ -88,12 +134,9 @@ func SequenceRunes(parsers ...Parser) Parser {
 // the surrounded value. This is equivalent to Sequence with a combiner
 // which returns the middle result.
 func Surround(left, parser, right Parser) Parser {
-	return Sequence(
-		func(results []Result, begin, end Scanner) Result {
-			return results[1]
-		},
-		left,
-		parser,
-		right,
-	)
+	return sequence(surroundCombiner, false, []Parser{left, parser, right})
+}
+
+func surroundCombiner(results []Result, begin, end Scanner) Result {
+	return results[1]
 }
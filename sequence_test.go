@@ -20,10 +20,11 @@ func TestSequence(t *testing.T) {
 
 		expected := Result{
 			Interface: []Result{
-				{Runes: []rune("a")},
-				{Runes: []rune("b")},
-				{Runes: []rune("c")},
+				{Runes: []rune("a"), Span: lineSpan(0, 1)},
+				{Runes: []rune("b"), Span: lineSpan(1, 2)},
+				{Runes: []rune("c"), Span: lineSpan(2, 3)},
 			},
+			Span: lineSpan(0, 3),
 		}
 
 		assert.True(t, r.Matched())
@@ -43,7 +44,7 @@ func TestSequence(t *testing.T) {
 		r, _ := p.Parse(s)
 
 		assert.False(t, r.Matched())
-		assert.EqualError(t, r.Err, "unexpected character 'a'")
+		assert.EqualError(t, r.Err, "line 1 col 2: expected 'b' but found 'a'")
 	})
 
 	t.Run("ignored", func(t *testing.T) {
@@ -59,9 +60,10 @@ func TestSequence(t *testing.T) {
 
 		expected := Result{
 			Interface: []Result{
-				{Runes: []rune("a")},
-				{Runes: []rune("c")},
+				{Runes: []rune("a"), Span: lineSpan(0, 1)},
+				{Runes: []rune("c"), Span: lineSpan(2, 3)},
 			},
+			Span: lineSpan(0, 3),
 		}
 
 		assert.True(t, r.Matched())
@@ -100,6 +102,7 @@ func TestSequenceRunes(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune("abc"),
+			Span:  lineSpan(0, 3),
 		}
 
 		assert.True(t, r.Matched())
@@ -118,7 +121,7 @@ func TestSequenceRunes(t *testing.T) {
 		r, _ := p.Parse(s)
 
 		assert.False(t, r.Matched())
-		assert.EqualError(t, r.Err, "unexpected character 'a'")
+		assert.EqualError(t, r.Err, "line 1 col 2: expected 'b' but found 'a'")
 	})
 }
 
@@ -152,6 +155,7 @@ func TestSurround(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune("foo"),
+			Span:  lineSpan(1, 4),
 		}
 
 		assert.True(t, r.Matched())
@@ -165,7 +169,7 @@ func TestSurround(t *testing.T) {
 		r, _ := p.Parse(s)
 
 		assert.False(t, r.Matched())
-		assert.EqualError(t, r.Err, "unexpected character 'd'")
+		assert.EqualError(t, r.Err, "line 1 col 5: expected ')' but found 'd'")
 	})
 
 	t.Run("nested", func(t *testing.T) {
@@ -185,6 +189,7 @@ func TestSurround(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune("baz"),
+			Span:  lineSpan(4, 7),
 		}
 
 		assert.True(t, r.Matched())
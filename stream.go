@@ -0,0 +1,188 @@
+package comb
+
+import (
+	"bufio"
+	"io"
+)
+
+// NewReaderScanner creates a new Scanner which lazily reads runes from r
+// as they are scanned. If r does not implement io.RuneReader, it is
+// wrapped in a bufio.Reader.
+//
+// Every rune read is buffered, so that any Scanner can be backtracked to,
+// until Release is called. A reader scanner shares its buffer between all
+// of the Scanners derived from it, so unlike other scanners, it must not be
+// used by multiple goroutines at once.
+func NewReaderScanner(r io.Reader) Scanner {
+	rr, ok := r.(io.RuneReader)
+	if !ok {
+		rr = bufio.NewReader(r)
+	}
+
+	return Scanner{
+		input: &input{stream: &runeStream{r: rr}},
+	}
+}
+
+// Release discards the input buffered before s, allowing it to be garbage
+// collected. It should be called once no Scanner before s will be used
+// again, for example after each record has been parsed from a large file.
+// Using a scanner before a released position panics.
+//
+// Within Parse and ParseAll, input is also released at commit points: when
+// a Cut is passed, and when a parser wrapped in Commit starts. Only the
+// input which no enclosing parser can return to is released, so a grammar
+// such as
+//
+//	records := comb.Many(nil, comb.Sequence(nil, key, comb.Cut(), value))
+//
+// only buffers the record being parsed. Parsers which may backtrack, such
+// as Or and Maybe, and those whose results hold the input they matched,
+// such as ManyRunes, keep their input buffered until they return. So do
+// Sequence and the repetitions when given a combiner, which may read the
+// input between the scanners it is given. Release called within a parse is
+// limited in the same way, so it is always safe.
+//
+// Release has no effect on scanners not created by NewReaderScanner.
+func (s Scanner) Release() {
+	if s.stream != nil {
+		s.stream.release(s.st.releasable(s.i))
+	}
+}
+
+// pin notes that the parser starting at s may return to it, so that the
+// input from s onward is not released until unpin is called. It returns
+// the pin, which is -1 if s is not a reader scanner.
+func (st *state) pin(s Scanner) int {
+	if st == nil || s.stream == nil {
+		return -1
+	}
+	st.pins = append(st.pins, s.i)
+	return len(st.pins) - 1
+}
+
+// repin moves a pin forward to s, as a repetition does for each match.
+func (st *state) repin(pin int, s Scanner) {
+	if pin >= 0 {
+		st.pins[pin] = s.i
+	}
+}
+
+// unpin removes a pin, along with any pins made after it.
+func (st *state) unpin(pin int) {
+	if pin >= 0 {
+		st.pins = st.pins[:pin]
+	}
+}
+
+// commit releases the input before s which no parser can return to.
+// Outside of a parse, nothing is known about the enclosing parsers,
+// so nothing is released.
+func (st *state) commit(s Scanner) {
+	if st != nil && s.stream != nil {
+		s.stream.release(st.releasable(s.i))
+	}
+}
+
+// releasable limits offset i to the outermost pin.
+func (st *state) releasable(i int) int {
+	if st != nil && len(st.pins) > 0 && st.pins[0] < i {
+		return st.pins[0]
+	}
+	return i
+}
+
+// runeStream holds the runes read from a reader which may still be scanned.
+// buf contains the runes starting at offset base, though only those from
+// offset min onward are still usable; the rest are dropped in batches.
+type runeStream struct {
+	r    io.RuneReader
+	buf  []rune
+	base int
+	min  int
+	err  error
+
+	// limit is the offset of the first rune beyond the input size limit
+	// of the current parse, or 0 if there is none. No rune after it is
+	// read, so the buffer cannot grow without bound.
+	limit    int
+	limitErr *LimitError
+}
+
+// fill reads runes until offset i is buffered. It returns the error from
+// the reader if it fails before then, or a *LimitError if offset i is
+// beyond the limit.
+func (rs *runeStream) fill(i int) error {
+	if i < rs.min {
+		panic("comb: scanner used after its position was released")
+	}
+
+	for i-rs.base >= len(rs.buf) {
+		if rs.err != nil {
+			return rs.err
+		}
+
+		if rs.limit > 0 && rs.base+len(rs.buf) > rs.limit {
+			return rs.limitErr
+		}
+
+		r, _, err := rs.r.ReadRune()
+		if err != nil {
+			rs.err = err
+			return err
+		}
+
+		rs.buf = append(rs.buf, r)
+	}
+
+	if rs.limit > 0 && i >= rs.limit {
+		return rs.limitErr
+	}
+
+	return nil
+}
+
+// setLimit limits the input to max runes after offset start, returning
+// the previous limit so that it can be restored.
+func (rs *runeStream) setLimit(start, max int) (int, *LimitError) {
+	limit, limitErr := rs.limit, rs.limitErr
+	if max > 0 {
+		rs.limit = start + max
+		rs.limitErr = &LimitError{Limit: InputSizeLimit, Max: max}
+	} else {
+		rs.limit, rs.limitErr = 0, nil
+	}
+	return limit, limitErr
+}
+
+func (rs *runeStream) slice(from, to int) []rune {
+	if from < rs.min {
+		panic("comb: scanner used after its position was released")
+	}
+	return rs.buf[from-rs.base : to-rs.base]
+}
+
+const minStreamDrop = 1024
+
+func (rs *runeStream) release(i int) {
+	if i <= rs.min {
+		return
+	}
+	rs.min = i
+
+	// Copying the remaining runes for every release would be costly,
+	// so only do so once enough of the buffer can be dropped. Slices
+	// returned by Between may still refer to the old array, so the
+	// runes are copied into a new one rather than moved.
+	drop := i - rs.base
+	if drop < minStreamDrop || drop < len(rs.buf)/2 {
+		return
+	}
+
+	keep := rs.buf[drop:]
+	buf := make([]rune, len(keep), 2*len(keep)+minStreamDrop)
+	copy(buf, keep)
+
+	rs.buf = buf
+	rs.base = i
+}
@@ -0,0 +1,185 @@
+package comb
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReaderScanner(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		runes := []rune("Hello, 世界")
+		s := NewReaderScanner(iotest.OneByteReader(strings.NewReader(string(runes))))
+
+		var r rune
+		next := s
+		var err error
+
+		for i := 0; i < len(runes); i++ {
+			assert.False(t, next.EOF())
+			r, next, err = next.Next()
+			assert.Equal(t, runes[i], r)
+			assert.Nil(t, err)
+		}
+
+		assert.True(t, next.EOF())
+		_, _, err = next.Next()
+		assert.Equal(t, io.EOF, err)
+
+		assert.Equal(t, runes, s.Between(next))
+	})
+
+	t.Run("backtrack", func(t *testing.T) {
+		p := Or(
+			Token("foobaz"),
+			Token("foobar"),
+		)
+		s := NewReaderScanner(strings.NewReader("foobar\nx"))
+
+		r, next := p.Parse(s)
+
+		expected := Result{
+			Runes: []rune("foobar"),
+			Span:  lineSpan(0, 6),
+		}
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, expected, r)
+
+		_, next, _ = next.Next()
+		assert.Equal(t, 2, next.Line())
+		assert.Equal(t, 1, next.Col())
+	})
+
+	t.Run("release", func(t *testing.T) {
+		line := SequenceRunes(OnePlusRunes(NotChar('\n')), Char('\n'))
+		s := NewReaderScanner(strings.NewReader(strings.Repeat("abcdef\n", 1000)))
+
+		var r Result
+		next := s
+
+		for !next.EOF() {
+			r, next = line.Parse(next)
+			assert.True(t, r.Matched())
+			assert.Equal(t, []rune("abcdef\n"), r.Runes)
+
+			next.Release()
+			assert.True(t, len(next.stream.buf) < 2*minStreamDrop)
+		}
+
+		assert.Panics(t, func() {
+			s.Next()
+		})
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		maxBuf := 0
+		check := ParserFunc(func(s Scanner) (Result, Scanner) {
+			if n := len(s.stream.buf); n > maxBuf {
+				maxBuf = n
+			}
+			return Result{Ignore: true}, s
+		})
+		record := Sequence(nil, Token("rec"), Cut(), OnePlusRunes(CharRange('0', '9')), Char(';'), check)
+		s := NewReaderScanner(strings.NewReader(strings.Repeat("rec12345;", 10000)))
+
+		r, next := Parse(Sequence(nil, Many(nil, record), EOF()), s)
+
+		assert.True(t, r.Matched())
+		assert.True(t, next.EOF())
+		assert.Len(t, r.Interface.([]Result)[0].Interface, 10000)
+		assert.True(t, maxBuf < 4*minStreamDrop, "buffered %d runes", maxBuf)
+	})
+
+	t.Run("commit pinned", func(t *testing.T) {
+		release := ParserFunc(func(s Scanner) (Result, Scanner) {
+			s.Release()
+			return Result{}, s
+		})
+		record := Sequence(nil, Token("rec"), Cut(), Char(';'), release)
+		input := strings.Repeat("rec;", 1000)
+
+		r, _ := Parse(ManyRunes(record), NewReaderScanner(strings.NewReader(input)))
+		assert.True(t, r.Matched())
+		assert.Equal(t, input, string(r.Runes))
+
+		combined := Many(func(results []Result, begin, end Scanner) Result {
+			return Result{Runes: begin.Between(end)}
+		}, record)
+
+		r, _ = Parse(combined, NewReaderScanner(strings.NewReader(input)))
+		assert.True(t, r.Matched())
+		assert.Equal(t, input, string(r.Runes))
+	})
+
+	t.Run("commit backtrack", func(t *testing.T) {
+		p := Or(
+			Sequence(nil, Sequence(nil, Token("a"), Cut(), Token("b")), Token("x")),
+			Token("aby"),
+		)
+
+		r, _ := Parse(p, NewReaderScanner(strings.NewReader("aby")))
+		assert.True(t, r.Matched())
+		assert.Equal(t, []rune("aby"), r.Runes)
+	})
+
+	t.Run("commit recover", func(t *testing.T) {
+		record := Sequence(nil, Token("rec"), Cut(), OnePlusRunes(CharRange('0', '9')), Char(';'))
+		input := "rec12x45;" + strings.Repeat("rec12345;", 1000)
+
+		r, _, errs := ParseAll(Many(nil, Recover(record, Char(';'))), NewReaderScanner(strings.NewReader(input)))
+		assert.True(t, r.Matched())
+		if assert.Len(t, errs, 1) {
+			assert.EqualError(t, errs[0], "line 1 col 6: expected one of '0'-'9', ';' but found 'x'")
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		errTest := errors.New("test")
+		s := NewReaderScanner(io.MultiReader(strings.NewReader("ab"), iotest.ErrReader(errTest)))
+
+		r, _ := OnePlusRunes(Char('a', 'b', 'c')).Parse(s)
+		assert.True(t, r.Matched())
+		assert.Equal(t, []rune("ab"), r.Runes)
+
+		r, _ = Token("abc").Parse(s)
+		assert.False(t, r.Matched())
+		assert.True(t, errors.Is(r.Err, errTest))
+	})
+
+	t.Run("FormatError", func(t *testing.T) {
+		s := NewReaderScanner(strings.NewReader("ab\ncd\nef"))
+
+		_, next := Token("ab\n").Parse(s)
+		next.Release()
+
+		r, next := Char('x').Parse(next)
+		assert.False(t, r.Matched())
+
+		expected := "line 2 col 1: expected 'x' but found 'c'\n" +
+			" 2 | cd\n" +
+			"   | ^\n"
+
+		assert.Equal(t, expected, FormatError(r, next, &ErrorFormat{Context: 2}))
+	})
+}
+
+func BenchmarkReaderScanner(b *testing.B) {
+	b.ReportAllocs()
+
+	line := SequenceRunes(OnePlusRunes(NotChar('\n')), Char('\n'))
+	input := strings.Repeat("Hello, 世界\n", 1000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		next := NewReaderScanner(strings.NewReader(input))
+		for !next.EOF() {
+			_, next = line.Parse(next)
+			next.Release()
+		}
+	}
+}
@@ -26,14 +26,19 @@ func TokenRunes(tokens ...[]rune) Parser {
 		}
 	}
 
+	expected := make([]string, len(tokens))
+	for i, tok := range tokens {
+		expected[i] = fmt.Sprintf("%q", string(tok))
+	}
+
 	if len(tokens) == 1 {
-		return singleToken(tokens[0])
+		return singleToken(tokens[0], expected)
 	}
 
-	return manyTokens(tokens)
+	return manyTokens(tokens, expected)
 }
 
-func singleToken(runes []rune) Parser {
+func singleToken(runes []rune, expected []string) Parser {
 	return ParserFunc(func(s Scanner) (Result, Scanner) {
 		var r rune
 		next := s
@@ -42,21 +47,19 @@ func singleToken(runes []rune) Parser {
 		for _, c := range runes {
 			r, next, err = next.Next()
 			if err != nil {
-				return Failed(err), next
+				return failedCause(s, expected, err), next
 			}
 
 			if r != c {
-				return Failed(tokenError(s.Between(next))), next
+				return Failed(tokenError(s, next, expected)), next
 			}
 		}
 
-		return Result{
-			Runes: s.Between(next),
-		}, next
+		return s.Result(next), next
 	})
 }
 
-func manyTokens(tokens [][]rune) Parser {
+func manyTokens(tokens [][]rune, expected []string) Parser {
 	t := buildTrie(tokens)
 
 	return ParserFunc(func(s Scanner) (Result, Scanner) {
@@ -69,26 +72,27 @@ func manyTokens(tokens [][]rune) Parser {
 		for !t.accept {
 			r, next, err = next.Next()
 			if err != nil {
-				return Failed(err), next
+				return failedCause(s, expected, err), next
 			}
 
 			t = t.find(r)
 			if t == nil {
-				return Failed(tokenError(s.Between(next))), next
+				return Failed(tokenError(s, next, expected)), next
 			}
 		}
 
-		return Result{
-			Runes: s.Between(next),
-		}, next
+		return s.Result(next), next
 	})
 }
 
-func tokenError(runes []rune) error {
-	return errorFunc(func() string {
-		prefix := string(runes)
-		return fmt.Sprintf("'%s' is not a prefix of any token", prefix)
-	})
+func tokenError(s, next Scanner, expected []string) error {
+	runes := s.Between(next)
+
+	return &ParseError{
+		Scanner:  s,
+		Expected: expected,
+		found:    runes,
+	}
 }
 
 type tokenTrie struct {
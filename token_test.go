@@ -1,8 +1,10 @@
 package comb
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -15,6 +17,7 @@ func matchingToken(t *testing.T, p Parser, str string) {
 
 	expected := Result{
 		Runes: []rune(str),
+		Span:  lineSpan(0, len([]rune(str))),
 	}
 
 	assert.True(t, r.Matched())
@@ -29,10 +32,11 @@ func notMatchingToken(t *testing.T, p Parser, str, prefix string) {
 	assert.False(t, r.Matched())
 
 	if prefix != "" {
-		errStr := fmt.Sprintf("'%s' is not a prefix of any token", prefix)
-		assert.EqualError(t, r.Err, errStr)
+		found := fmt.Sprintf(" but found %q", prefix)
+		assert.True(t, strings.HasPrefix(r.Err.Error(), "line 1 col 1: expected "))
+		assert.True(t, strings.HasSuffix(r.Err.Error(), found))
 	} else {
-		assert.Equal(t, r.Err, io.EOF)
+		assert.True(t, errors.Is(r.Err, io.EOF))
 	}
 }
 
@@ -84,6 +88,7 @@ func TestSingleToken(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune("foobar"),
+			Span:  lineSpan(0, 6),
 		}
 
 		assert.True(t, r.Matched())
@@ -132,6 +137,7 @@ func TestManyTokens(t *testing.T) {
 
 		expected := Result{
 			Runes: []rune("foobar"),
+			Span:  lineSpan(0, 6),
 		}
 
 		assert.True(t, r.Matched())
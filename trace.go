@@ -0,0 +1,121 @@
+package comb
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TraceEvent describes a labelled parser being entered or exited during
+// a traced parse. Parsers are labelled with Label or LabelOf.
+type TraceEvent struct {
+	// Label is the label of the parser.
+	Label string
+
+	// Depth is the number of labelled parsers running around this one.
+	Depth int
+
+	// Exit is false when the parser is entered, and true when it returns.
+	Exit bool
+
+	// Span is the input consumed by the parser. When the parser is entered,
+	// or if it failed, the span is empty, at the parser's position.
+	Span Span
+
+	// Text is the text consumed by the parser, if it matched.
+	Text string
+
+	// Err is the error of the parser, if it failed.
+	Err error
+}
+
+// maxTraceText is the number of runes of consumed text shown in a trace
+// before it is truncated.
+const maxTraceText = 40
+
+// String formats the event as a line of a trace, such as:
+//
+//	expr 1:1
+//	expr 1:1-1:6 matched "1 + 2"
+//	expr 1:1 failed: line 1 col 1: expected expression but found ')'
+func (e TraceEvent) String() string {
+	switch {
+	case !e.Exit:
+		return e.Label + " " + e.Span.Start.String()
+	case e.Err != nil:
+		return e.Label + " " + e.Span.Start.String() + " failed: " + e.Err.Error()
+	}
+
+	text := []rune(e.Text)
+	if len(text) > maxTraceText {
+		return fmt.Sprintf("%s %s matched %q...", e.Label, e.Span, string(text[:maxTraceText]))
+	}
+	return fmt.Sprintf("%s %s matched %q", e.Label, e.Span, e.Text)
+}
+
+// Trace writes a trace of the parse to w, with a line for each labelled
+// parser as it is entered and exited, indented to show how the parsers
+// are nested:
+//
+//	expr 1:1
+//	  number 1:1
+//	  number 1:1-1:2 matched "1"
+//	expr 1:1-1:2 matched "1"
+//
+// Errors writing to w are ignored.
+func Trace(w io.Writer) Option {
+	return TraceFunc(func(e TraceEvent) {
+		fmt.Fprintf(w, "%s%s\n", strings.Repeat("  ", e.Depth), e)
+	})
+}
+
+// TraceFunc calls fn as each labelled parser is entered and exited
+// during the parse.
+func TraceFunc(fn func(TraceEvent)) Option {
+	return func(st *state) {
+		st.trace = fn
+	}
+}
+
+// traceEnter traces entering the parser labelled name at s. The input
+// from s is pinned so that the text matched can be traced; the pin
+// returned must be given to traceExit.
+func (st *state) traceEnter(name string, s Scanner) int {
+	if st == nil || st.trace == nil {
+		return -1
+	}
+
+	st.trace(TraceEvent{
+		Label: name,
+		Depth: st.traceDepth,
+		Span:  s.Span(s),
+	})
+	st.traceDepth++
+	return st.pin(s)
+}
+
+// traceExit traces the result of the parser labelled name, which was
+// entered at s.
+func (st *state) traceExit(name string, pin int, s, next Scanner, r Result) {
+	if st == nil || st.trace == nil {
+		return
+	}
+
+	st.traceDepth--
+
+	e := TraceEvent{
+		Label: name,
+		Depth: st.traceDepth,
+		Exit:  true,
+		Span:  s.Span(s),
+		Err:   r.Err,
+	}
+
+	if r.Matched() {
+		e.Span = s.Span(next)
+		e.Text = s.BetweenString(next)
+	}
+
+	st.unpin(pin)
+	st.trace(e)
+}
@@ -0,0 +1,89 @@
+package comb
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrace(t *testing.T) {
+	number := Label("number", OnePlusRunes(CharRange('0', '9')))
+	sum := Label("sum", Sequence(nil, number, Char('+'), number))
+
+	t.Run("writer", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		r, _ := Parse(sum, NewStringScanner("1+23"), Trace(&buf))
+
+		expected := `sum 1:1
+  number 1:1
+  number 1:1-1:2 matched "1"
+  number 1:3
+  number 1:3-1:5 matched "23"
+sum 1:1-1:5 matched "1+23"
+`
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		Parse(sum, NewStringScanner("1+x"), Trace(&buf))
+
+		expected := `sum 1:1
+  number 1:1
+  number 1:1-1:2 matched "1"
+  number 1:3
+  number 1:3 failed: line 1 col 3: expected number but found 'x'
+sum 1:1 failed: line 1 col 3: expected number but found 'x'
+`
+
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("func", func(t *testing.T) {
+		var events []TraceEvent
+
+		Parse(sum, NewStringScanner("1+2"), TraceFunc(func(e TraceEvent) {
+			events = append(events, e)
+		}))
+
+		assert.Len(t, events, 6)
+		assert.Equal(t, TraceEvent{
+			Label: "number",
+			Depth: 1,
+			Exit:  true,
+			Span:  lineSpan(2, 3),
+			Text:  "2",
+		}, events[4])
+	})
+
+	t.Run("typed", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		Parse(LabelOf("digit", Text(CharRange('0', '9'))), NewStringScanner("7"), Trace(&buf))
+
+		assert.Equal(t, "digit 1:1\ndigit 1:1-1:2 matched \"7\"\n", buf.String())
+	})
+
+	t.Run("untraced", func(t *testing.T) {
+		r, _ := sum.Parse(NewStringScanner("1+2"))
+
+		assert.True(t, r.Matched())
+	})
+}
+
+func TestTraceEventString(t *testing.T) {
+	e := TraceEvent{
+		Label: "text",
+		Exit:  true,
+		Span:  lineSpan(0, 50),
+		Text:  strings.Repeat("a", 50),
+	}
+
+	assert.Equal(t, `text 1:1-1:51 matched "`+strings.Repeat("a", 40)+`"...`, e.String())
+}
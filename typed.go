@@ -0,0 +1,303 @@
+package comb
+
+// TypedParser is a Parser which produces a value of type T. It can be used
+// anywhere a Parser can, in which case the value is placed in the result's
+// Interface. Combining typed parsers with Map, Seq2, ManyOf, and the like
+// keeps values typed, so grammar mistakes are caught by the compiler rather
+// than by failed type assertions.
+type TypedParser[T any] struct {
+	fn func(Scanner) (T, Result, Scanner)
+
+	// name is the label given by LabelOf, and ref the parser referred to
+	// by ReferenceOf or LeftRecOf, used to name the parser in messages.
+	name string
+	ref  *TypedParser[T]
+}
+
+// Parse implements Parser, placing the parsed value in Interface.
+func (p TypedParser[T]) Parse(s Scanner) (Result, Scanner) {
+	v, r, next := p.fn(s)
+	if r.Matched() {
+		r.Interface = v
+	}
+	return r, next
+}
+
+func (p TypedParser[T]) parserName() string {
+	if p.ref != nil {
+		return p.ref.parserName()
+	}
+	return p.name
+}
+
+// ParseValue parses, returning the typed value along with the result.
+// The value is only meaningful if the result matched.
+func (p TypedParser[T]) ParseValue(s Scanner) (T, Result, Scanner) {
+	return p.fn(s)
+}
+
+// TypedParserFunc turns a typed parser function into a TypedParser.
+func TypedParserFunc[T any](fn func(Scanner) (T, Result, Scanner)) TypedParser[T] {
+	return TypedParser[T]{fn: fn}
+}
+
+// Typed turns a Parser into a TypedParser, using fn to get a value
+// from each matched result.
+func Typed[T any](parser Parser, fn func(Result) T) TypedParser[T] {
+	return TypedParserFunc(func(s Scanner) (T, Result, Scanner) {
+		var v T
+
+		r, next := parser.Parse(s)
+		if r.Matched() {
+			v = fn(r)
+		}
+
+		return v, r, next
+	})
+}
+
+// Text turns a Parser into a TypedParser of the text it captures.
+func Text(parser Parser) TypedParser[string] {
+	return Typed(parser, func(r Result) string {
+		return r.AsString()
+	})
+}
+
+// Pure matches nothing, producing v.
+func Pure[T any](v T) TypedParser[T] {
+	return TypedParserFunc(func(s Scanner) (T, Result, Scanner) {
+		return v, Result{Span: s.Span(s)}, s
+	})
+}
+
+// ReferenceOf is like Reference, but for a TypedParser.
+func ReferenceOf[T any](p *TypedParser[T]) TypedParser[T] {
+	ref := TypedParserFunc(func(s Scanner) (T, Result, Scanner) {
+		if r, ok := s.st.enter(s); !ok {
+			var zero T
+			return zero, r, s
+		}
+
+		v, r, next := p.fn(s)
+		s.st.leave()
+		return v, r, next
+	})
+	ref.ref = p
+	return ref
+}
+
+// LabelOf is like Label, but for a typed parser.
+func LabelOf[T any](name string, p TypedParser[T]) TypedParser[T] {
+	expected := []string{name}
+
+	labelled := TypedParserFunc(func(s Scanner) (T, Result, Scanner) {
+		pin := s.st.traceEnter(name, s)
+		s.st.profileEnter(name)
+
+		mark := s.st.begin()
+		v, r, next := p.fn(s)
+
+		pe := s.st.label(mark, s, expected, r.Err)
+		if !r.Matched() {
+			r.Err = pe
+		}
+
+		s.st.profileExit(name, s, next, r)
+		s.st.traceExit(name, pin, s, next, r)
+		return v, r, next
+	})
+	labelled.name = name
+	return labelled
+}
+
+// Map transforms the value of a typed parser with fn.
+func Map[A, B any](p TypedParser[A], fn func(A) B) TypedParser[B] {
+	return TypedParserFunc(func(s Scanner) (B, Result, Scanner) {
+		var b B
+
+		a, r, next := p.fn(s)
+		if r.Matched() {
+			b = fn(a)
+		}
+
+		return b, r, next
+	})
+}
+
+// Bind runs p, then passes its value to fn to choose the parser to run
+// next, producing that parser's value. This allows what is parsed to
+// depend on what has already been parsed, such as a length prefix.
+func Bind[A, B any](p TypedParser[A], fn func(A) TypedParser[B]) TypedParser[B] {
+	return TypedParserFunc(func(s Scanner) (B, Result, Scanner) {
+		mark := s.st.begin()
+		var b B
+
+		a, r, next := p.fn(s)
+		if !r.Matched() {
+			return b, s.st.fail(mark, r), next
+		}
+
+		b, r, next = fn(a).fn(next)
+		if !r.Matched() {
+			return b, s.st.fail(mark, r), next
+		}
+
+		s.st.end(mark, nil)
+		r.Span = s.Span(next)
+		return b, r, next
+	})
+}
+
+// Seq2 runs two typed parsers in sequence, combining their values with fn.
+func Seq2[A, B, C any](pa TypedParser[A], pb TypedParser[B], fn func(A, B) C) TypedParser[C] {
+	return TypedParserFunc(func(s Scanner) (C, Result, Scanner) {
+		mark := s.st.begin()
+		var c C
+
+		a, r, next := pa.fn(s)
+		if !r.Matched() {
+			return c, s.st.fail(mark, r), next
+		}
+
+		b, r, next := pb.fn(next)
+		if !r.Matched() {
+			return c, s.st.fail(mark, r), next
+		}
+
+		s.st.end(mark, nil)
+		return fn(a, b), Result{Span: s.Span(next)}, next
+	})
+}
+
+// Seq3 runs three typed parsers in sequence, combining their values with fn.
+func Seq3[A, B, C, D any](pa TypedParser[A], pb TypedParser[B], pc TypedParser[C], fn func(A, B, C) D) TypedParser[D] {
+	return TypedParserFunc(func(s Scanner) (D, Result, Scanner) {
+		mark := s.st.begin()
+		var d D
+
+		a, r, next := pa.fn(s)
+		if !r.Matched() {
+			return d, s.st.fail(mark, r), next
+		}
+
+		b, r, next := pb.fn(next)
+		if !r.Matched() {
+			return d, s.st.fail(mark, r), next
+		}
+
+		c, r, next := pc.fn(next)
+		if !r.Matched() {
+			return d, s.st.fail(mark, r), next
+		}
+
+		s.st.end(mark, nil)
+		return fn(a, b, c), Result{Span: s.Span(next)}, next
+	})
+}
+
+// OrOf is like Or, but for typed parsers of the same type.
+func OrOf[T any](parsers ...TypedParser[T]) TypedParser[T] {
+	untyped := make([]Parser, len(parsers))
+	for i, p := range parsers {
+		untyped[i] = p
+	}
+
+	return Typed(Or(untyped...), valueOf[T])
+}
+
+// valueOf returns the value a TypedParser placed in a result's Interface.
+// A nil value of an interface type T is stored as a nil Interface, so the
+// zero value of T is returned for it.
+func valueOf[T any](r Result) T {
+	v, _ := r.Interface.(T)
+	return v
+}
+
+// MaybeOf is like Maybe, but for a typed parser. If the parser does not
+// match, the zero value of T is produced.
+func MaybeOf[T any](p TypedParser[T]) TypedParser[T] {
+	return TypedParserFunc(func(s Scanner) (T, Result, Scanner) {
+		pin := s.st.pin(s)
+		v, r, next := p.fn(s)
+		s.st.unpin(pin)
+		if r.Matched() || r.Committed {
+			return v, r, next
+		}
+
+		s.st.record(r.Err)
+
+		var zero T
+		return zero, Result{Span: s.Span(s)}, s
+	})
+}
+
+// ManyOf is like Many, but for a typed parser, producing a slice
+// of the values of the 0+ matches.
+func ManyOf[T any](p TypedParser[T]) TypedParser[[]T] {
+	return TypedParserFunc(func(s Scanner) ([]T, Result, Scanner) {
+		pin := s.st.pin(s)
+		var values []T
+		next := s
+
+		for {
+			if r, ok := s.st.step(next); !ok {
+				s.st.unpin(pin)
+				return nil, r, next
+			}
+
+			v, r, maybeNext := p.fn(next)
+			if r.Committed {
+				s.st.unpin(pin)
+				return nil, r, maybeNext
+			}
+			if !r.Matched() {
+				s.st.record(r.Err)
+				break
+			}
+			if stalled(p, next, maybeNext) {
+				break
+			}
+
+			next = maybeNext
+			values = append(values, v)
+			s.st.repin(pin, next)
+		}
+
+		s.st.unpin(pin)
+		return values, Result{Span: s.Span(next)}, next
+	})
+}
+
+// OnePlusOf is like ManyOf, but requires at least one match.
+func OnePlusOf[T any](p TypedParser[T]) TypedParser[[]T] {
+	many := ManyOf(p)
+
+	return TypedParserFunc(func(s Scanner) ([]T, Result, Scanner) {
+		v, r, next := p.fn(s)
+		if !r.Matched() {
+			return nil, r, next
+		}
+
+		rest, r, next := many.fn(next)
+		if !r.Matched() {
+			return nil, r, next
+		}
+
+		values := make([]T, 0, len(rest)+1)
+		values = append(values, v)
+		values = append(values, rest...)
+
+		return values, Result{Span: s.Span(next)}, next
+	})
+}
+
+// LeftRecOf is like LeftRec, but for a TypedParser.
+func LeftRecOf[T any](p *TypedParser[T]) TypedParser[T] {
+	lr := leftRec(leftRecID{p: p}, func() Parser {
+		return *p
+	})
+
+	typed := Typed(lr, valueOf[T])
+	typed.ref = p
+	return typed
+}
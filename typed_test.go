@@ -0,0 +1,191 @@
+package comb
+
+import (
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var typedNumber = Map(Text(OnePlusRunes(CharRange('0', '9'))), func(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+})
+
+func TestTyped(t *testing.T) {
+	t.Run("ParseValue", func(t *testing.T) {
+		s := NewStringScanner("123a")
+
+		v, r, next := typedNumber.ParseValue(s)
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, 123, v)
+		assert.Equal(t, lineSpan(0, 3), r.Span)
+		assert.False(t, next.EOF())
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		p := Sequence(nil, typedNumber, Char(';'))
+		s := NewStringScanner("42;")
+
+		r, _ := p.Parse(s)
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, 42, r.Interface.([]Result)[0].Interface)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		s := NewStringScanner("a")
+
+		v, r, _ := typedNumber.ParseValue(s)
+
+		assert.False(t, r.Matched())
+		assert.Equal(t, 0, v)
+		assert.EqualError(t, r.Err, "line 1 col 1: expected '0'-'9' but found 'a'")
+	})
+}
+
+func TestSeq(t *testing.T) {
+	type pair struct {
+		key   string
+		value int
+	}
+
+	key := Text(OnePlusRunes(CharRange('a', 'z')))
+	eq := Text(Char('='))
+
+	p := Seq3(key, eq, typedNumber, func(k, _ string, v int) pair {
+		return pair{k, v}
+	})
+
+	t.Run("match", func(t *testing.T) {
+		s := NewStringScanner("abc=12")
+
+		v, r, next := p.ParseValue(s)
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, pair{"abc", 12}, v)
+		assert.Equal(t, lineSpan(0, 6), r.Span)
+		assert.True(t, next.EOF())
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		s := NewStringScanner("abc=")
+
+		r, _ := Parse(p, s)
+
+		assert.False(t, r.Matched())
+		assert.EqualError(t, r.Err, "line 1 col 5: expected '0'-'9' but found EOF")
+	})
+
+	t.Run("Seq2", func(t *testing.T) {
+		p := Seq2(key, MaybeOf(typedNumber), func(k string, n int) string {
+			return k + strconv.Itoa(n)
+		})
+
+		v, r, _ := p.ParseValue(NewStringScanner("x7"))
+		assert.True(t, r.Matched())
+		assert.Equal(t, "x7", v)
+
+		v, r, _ = p.ParseValue(NewStringScanner("y"))
+		assert.True(t, r.Matched())
+		assert.Equal(t, "y0", v)
+	})
+}
+
+func TestBind(t *testing.T) {
+	// A digit giving the number of letters to follow.
+	p := Bind(typedNumber, func(n int) TypedParser[string] {
+		return Text(Take(n))
+	})
+
+	v, r, next := p.ParseValue(NewStringScanner("3abcd"))
+
+	assert.True(t, r.Matched())
+	assert.Equal(t, "abc", v)
+	assert.Equal(t, lineSpan(0, 4), r.Span)
+	assert.False(t, next.EOF())
+
+	_, r, _ = p.ParseValue(NewStringScanner("5abc"))
+	assert.False(t, r.Matched())
+}
+
+func TestManyOf(t *testing.T) {
+	item := Map(
+		Seq2(typedNumber, MaybeOf(Text(Char(','))), func(n int, _ string) int { return n }),
+		func(n int) int { return n * 2 },
+	)
+
+	t.Run("ManyOf", func(t *testing.T) {
+		v, r, next := ManyOf(item).ParseValue(NewStringScanner("1,2,3"))
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, []int{2, 4, 6}, v)
+		assert.True(t, next.EOF())
+
+		v, r, _ = ManyOf(item).ParseValue(NewStringScanner("x"))
+		assert.True(t, r.Matched())
+		assert.Empty(t, v)
+	})
+
+	t.Run("OnePlusOf", func(t *testing.T) {
+		v, r, _ := OnePlusOf(item).ParseValue(NewStringScanner("1,2"))
+
+		assert.True(t, r.Matched())
+		assert.Equal(t, []int{2, 4}, v)
+
+		_, r, _ = OnePlusOf(item).ParseValue(NewStringScanner("x"))
+		assert.False(t, r.Matched())
+	})
+}
+
+func TestOrOf(t *testing.T) {
+	var value TypedParser[int]
+	value = OrOf(
+		typedNumber,
+		Seq3(Text(Char('(')), ReferenceOf(&value), Text(Char(')')), func(_ string, n int, _ string) int {
+			return -n
+		}),
+		Map(Text(Char('x')), func(string) int { return 100 }),
+	)
+
+	v, r, _ := value.ParseValue(NewStringScanner("((7))"))
+	assert.True(t, r.Matched())
+	assert.Equal(t, 7, v)
+
+	v, r, _ = value.ParseValue(NewStringScanner("(x)"))
+	assert.True(t, r.Matched())
+	assert.Equal(t, -100, v)
+
+	v, r, _ = Pure(5).ParseValue(NewStringScanner("abc"))
+	assert.True(t, r.Matched())
+	assert.Equal(t, 5, v)
+
+	t.Run("nil interface", func(t *testing.T) {
+		p := OrOf(
+			Map(Text(Char('a')), func(string) error { return nil }),
+			Map(Text(Char('b')), func(string) error { return io.EOF }),
+		)
+
+		v, r, _ := p.ParseValue(NewStringScanner("a"))
+		assert.True(t, r.Matched())
+		assert.Nil(t, v)
+
+		v, r, _ = p.ParseValue(NewStringScanner("b"))
+		assert.True(t, r.Matched())
+		assert.Equal(t, io.EOF, v)
+	})
+}
+
+func TestLabelOf(t *testing.T) {
+	p := LabelOf("number", typedNumber)
+
+	v, r, _ := p.ParseValue(NewStringScanner("12"))
+	assert.True(t, r.Matched())
+	assert.Equal(t, 12, v)
+
+	_, r, _ = p.ParseValue(NewStringScanner("x"))
+	assert.False(t, r.Matched())
+	assert.EqualError(t, r.Err, "line 1 col 1: expected number but found 'x'")
+}